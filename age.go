@@ -0,0 +1,55 @@
+package excel2json
+
+import (
+	"excel/helper"
+	"fmt"
+	"time"
+)
+
+// maxAge is the age in years above which the age at an event is implausible
+const maxAge = 110
+
+// ageInYears returns the completed years from the birth date to the date, both YYYY-MM-DD,
+// and false if a date cannot be parsed or the date is before the birth date.
+func ageInYears(birth string, date string) (int, bool) {
+	b, err := time.Parse("2006-01-02", birth)
+	if err != nil {
+		return 0, false
+	}
+	d, err := time.Parse("2006-01-02", date)
+	if err != nil || d.Before(b) {
+		return 0, false
+	}
+	years := d.Year() - b.Year()
+	// the birthday of the year of the date has not come yet
+	if d.Month() < b.Month() || (d.Month() == b.Month() && d.Day() < b.Day()) {
+		years--
+	}
+	return years, true
+}
+
+// setAges sets the age at event of every event from the birth date in its PTID.
+// An event before the birth date or at an age above maxAge gets a fix message.
+func setAges() {
+	for _, ev := range allEvents() {
+		*ev.AgeAtEvent = nil
+		birth, ok := helper.BirthDate(*ev.PTID, opts.CenturyPivot)
+		if !ok || *ev.Date == "" {
+			continue
+		}
+		age, ok := ageInYears(birth, *ev.Date)
+		if !ok {
+			if *ev.Date < birth {
+				msg := errMessage{"date", "the date is before the birth date '" + birth + "' from the PTID"}
+				*ev.Fix = append(*ev.Fix, msg)
+			}
+			continue
+		}
+		*ev.AgeAtEvent = &age
+		if age > maxAge {
+			msg := errMessage{"age_at_event", fmt.Sprint("implausible age at the event: ", age,
+				" years, the birth date from the PTID is '", birth, "'")}
+			*ev.Fix = append(*ev.Fix, msg)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package excel2json
+
+import (
+	"excel/helper"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// AuditColumns checks the header rows of the sheets of all excel files in a folder that
+// match a sheet class against the columns file of o.Columns. It writes to w each unexpected
+// column name with the sheets that have it, and the column names of the columns file that no sheet has.
+// Returns the number of unexpected column names.
+func AuditColumns(e *log.Logger, dirPath string, o Options, w io.Writer) int {
+	opts = o
+	loadSheetClasses(e)
+	columns, err := helper.ReadLines(opts.Columns)
+	helper.CheckErr(e, err)
+
+	// unexpected column names and the sheets that have them
+	unexpected := map[string][]string{}
+	// column names of the columns file that match a column of a sheet
+	used := map[string]bool{}
+	for _, path := range excelFiles(dirPath) {
+		xlFile := openWorkbook(e, path)
+		for j, sheet := range xlFile.Sheets {
+			sheetType, keys := helper.CheckSheetType(e, path, j, sheet, sheetClasses)
+			if sheetType == "" {
+				continue
+			}
+			where := fmt.Sprint(path, " sheet #", j+1)
+			for _, k := range helper.UnexpectedColumns(keys, columns) {
+				unexpected[k] = append(unexpected[k], where)
+			}
+			for _, c := range columns {
+				for _, k := range keys {
+					if helper.StringInSlice(1, k, []string{c}) {
+						used[c] = true
+					}
+				}
+			}
+		}
+	}
+
+	names := []string{}
+	for k := range unexpected {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	t := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(t, "UNEXPECTED COLUMN\tSHEETS\tWHERE")
+	for _, k := range names {
+		fmt.Fprintf(t, "%q\t%d\t%s\n", k, len(unexpected[k]), strings.Join(unexpected[k], "; "))
+	}
+	t.Flush()
+
+	unused := []string{}
+	for _, c := range columns {
+		if c != "" && !used[c] {
+			unused = append(unused, c)
+		}
+	}
+	if len(unused) > 0 {
+		fmt.Fprintln(w, "columns of the columns file that no sheet has:", strings.Join(unused, ", "))
+	}
+	return len(names)
+}
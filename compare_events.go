@@ -40,35 +40,55 @@ func (a death) earlyDeathInfo() string {
 	return s
 }
 
+// sameAs returns true if two followup events or
+// two last_known_alive events are the same event.
+// No need to check status for followup events.
+func (a followups) sameAs(b followups) bool {
+	return a.Coag == b.Coag && a.Date == b.Date && a.DateEst == b.DateEst &&
+		a.PTID == b.PTID && a.Plat == b.Plat && a.PoNYHA == b.PoNYHA
+}
+
 // CompareFollowups checks if two followup events or
 // two last_known_alive events are duplicate.
-// No need to check status for followup events.
 func (a followups) CompareFollowups(s []followups) bool {
 	for i, b := range s {
-		if a.Coag == b.Coag && a.Date == b.Date && a.DateEst == b.DateEst &&
-			a.PTID == b.PTID && a.Plat == b.Plat && a.PoNYHA == b.PoNYHA {
-			s[i].Source.Path = append(s[i].Source.Path, a.Source.Path[0])
+		if a.sameAs(b) {
+			s[i].Source.add(a.Source)
 			return true
 		}
 	}
 	return false
 }
 
+// sameAs returns true if two death events are the same event.
+func (a death) sameAs(b death) bool {
+	return a.Date == b.Date && a.PTID == b.PTID && a.Operative == b.Operative &&
+		a.PrmDth == b.PrmDth && a.Reason == b.Reason
+}
+
+// samePerson returns true if two death events are of the same person.
+func (a death) samePerson(b death) bool {
+	return a.PTID == b.PTID && a.MRN == b.MRN && a.ResearchID == b.ResearchID
+}
+
 // CompareDeath checks if two death events are duplicate.
 func (a *death) CompareDeath(s *[]death) bool {
 	// i is the index of b
 	for i, b := range *s {
 		if a == &b {
-			(*s)[i].Source.Path = append((*s)[i].Source.Path, a.Source.Path[0])
+			(*s)[i].Source.add(a.Source)
 			return true
-		} else if (*a).Date == b.Date && (*a).PTID == b.PTID && (*a).Operative == b.Operative &&
-			(*a).PrmDth == b.PrmDth && (*a).Reason == b.Reason {
-			(*s)[i].Source.Path = append((*s)[i].Source.Path, a.Source.Path[0])
+		} else if (*a).sameAs(b) {
+			(*s)[i].Source.add(a.Source)
 			return true
 			// same person with different death date
-		} else if (*a).Date != b.Date && (*a).PTID == b.PTID && (*a).MRN == b.MRN && (*a).ResearchID == b.ResearchID {
+		} else if (*a).Date != b.Date && (*a).samePerson(b) {
 			// how to compare 2 dates?
 			if helper.DateLaterThan(b.Date, (*a).Date) {
+				// a is dropped, create a fix event of its date
+				if f := (*a).dateConflict(b); !f.CompareFix(allFix) {
+					allFix = append(allFix, f)
+				}
 				earlyDeath := (*a).earlyDeathInfo()
 				for j, e := range b.Fix {
 					if e.Field == "date" {
@@ -80,6 +100,10 @@ func (a *death) CompareDeath(s *[]death) bool {
 				(*s)[i].Fix = append((*s)[i].Fix, msg)
 				return true
 			} else if helper.DateLaterThan((*a).Date, b.Date) {
+				// b is dropped, create a fix event of its date
+				if f := b.dateConflict(*a); !f.CompareFix(allFix) {
+					allFix = append(allFix, f)
+				}
 				earlyDeath := b.earlyDeathInfo() // info of b
 
 				for _, e := range b.Fix {
@@ -103,64 +127,142 @@ func (a *death) CompareDeath(s *[]death) bool {
 	return false
 }
 
+// sameAs returns true if two stroke events or two tia events are the same event.
+func (a te) sameAs(b te) bool {
+	return a.Agents == b.Agents && a.Date == b.Date && a.When == b.When &&
+		a.Outcome == b.Outcome && a.PTID == b.PTID
+}
+
 // CompareTE checks if two stroke events or two tia events are duplicate
 func (a te) CompareTE(s []te) bool {
 	for i, b := range s {
-		if a.Agents == b.Agents && a.Date == b.Date && a.When == b.When &&
-			a.Outcome == b.Outcome && a.PTID == b.PTID {
-			s[i].Source.Path = append(s[i].Source.Path, a.Source.Path[0])
+		if a.sameAs(b) {
+			s[i].Source.add(a.Source)
 			return true
 		}
 	}
 	return false
 }
 
-// CompareEvents checks if two events (including SBE, lost_to_followup, FUMI,
-// FUPACE, SVD, PVL, DVT, ARH, THRM, HEML, Fix) are duplicate
+// sameAs returns true if two events (including SBE, FUMI, FUPACE, SVD, PVL,
+// DVT, ARH, THRM, HEML) are the same event.
+func (a general) sameAs(b general) bool {
+	// two names of the same organism of the organism table are the same organism
+	if a.Species != nil && b.Species != nil {
+		return *(a.Species) == *(b.Species) && a.Date == b.Date &&
+			a.PTID == b.PTID && a.Msg == b.Msg && a.Code == b.Code
+	} else if a.Organism == nil && b.Organism == nil {
+		return a.Date == b.Date && a.PTID == b.PTID && a.Msg == b.Msg &&
+			a.Code == b.Code
+	} else if a.Organism != nil && b.Organism != nil {
+		return *(a.Organism) == *(b.Organism) && a.Date == b.Date &&
+			a.PTID == b.PTID && a.Msg == b.Msg && a.Code == b.Code
+	}
+	return false
+}
+
+// CompareEvents checks if two events (including SBE, FUMI,
+// FUPACE, SVD, PVL, DVT, ARH, THRM, HEML) are duplicate
 func (a general) CompareEvents(s []general) bool {
 	for i, b := range s {
-		if a.Organism == nil && b.Organism == nil {
-			if a.Date == b.Date && a.PTID == b.PTID && a.Msg == b.Msg &&
-				a.Code == b.Code {
-				s[i].Source.Path = append(s[i].Source.Path, a.Source.Path[0])
-				return true
-			}
-		} else if a.Organism != nil && b.Organism != nil {
-			if *(a.Organism) == *(b.Organism) && a.Date == b.Date &&
-				a.PTID == b.PTID && a.Msg == b.Msg && a.Code == b.Code {
-				s[i].Source.Path = append(s[i].Source.Path, a.Source.Path[0])
-				return true
-			}
+		if a.sameAs(b) {
+			s[i].Source.add(a.Source)
+			return true
 		}
 	}
 	return false
 }
 
-// CompareOperation checks if two operation events are duplicate
+// sameAs returns true if two operation events are the same event.
+func (a operation) sameAs(b operation) bool {
+	return a.Date == b.Date && a.PTID == b.PTID &&
+		a.Surgeon == b.Surgeon && reflect.DeepEqual(a.Fix, b.Fix)
+}
+
+// sameSurgery returns true if two operation events are the same surgery
+// read from an operative sheet and from a follow_up sheet.
+func (a operation) sameSurgery(b operation) bool {
+	return a.Date == b.Date && a.PTID == b.PTID &&
+		(a.Source.Type == "operative") != (b.Source.Type == "operative")
+}
+
+// merge merges o, the same surgery read from another type of sheet, into a:
+// the fields of the operative sheet, the re-operation fields of the follow_up sheet
+// and the fix messages of both are kept.
+func (a *operation) merge(o operation) {
+	if o.Source.Type == "operative" {
+		// keep the surgeries and the notes of the re-operation if the operative sheet has none
+		if o.Surgeries != nil {
+			a.Surgeries = o.Surgeries
+		}
+		if o.Notes != nil {
+			a.Notes = o.Notes
+		}
+		a.Surgeon, a.PeriopID, a.Discharge = o.Surgeon, o.PeriopID, o.Discharge
+		a.DateEst = o.DateEst
+		a.Source.Type = o.Source.Type
+	} else if a.Source.Type == "operative" {
+		// the fields that only re-operations of follow_up sheets have
+		a.Surgery, a.Reason, a.Survival = o.Surgery, o.Reason, o.Survival
+		if a.Surgeries == nil {
+			a.Surgeries = o.Surgeries
+		}
+		if a.Notes == nil {
+			a.Notes = o.Notes
+		}
+	}
+	a.Fix = append(a.Fix, o.Fix...)
+	a.Source.add(o.Source)
+}
+
+// CompareOperation checks if two operation events are duplicate,
+// or the same surgery from an operative sheet and a follow_up sheet
 func (a operation) CompareOperation(s []operation) bool {
 	for i, b := range s {
-		if a.Date == b.Date && a.PTID == b.PTID &&
-			a.Surgeon == b.Surgeon && reflect.DeepEqual(a.Fix, b.Fix) {
-			s[i].Source.Path = append(s[i].Source.Path, a.Source.Path[0])
+		if a.sameAs(b) {
+			s[i].Source.add(a.Source)
+			return true
+		} else if a.sameSurgery(b) {
+			s[i].merge(a)
 			return true
 		}
 	}
 	return false
 }
 
+// sameAs returns true if two lost_to_followup events are the same event.
+func (a lostFollowup) sameAs(b lostFollowup) bool {
+	if a.LkaDate == nil && b.LkaDate == nil {
+		return a.PTID == b.PTID
+	} else if a.LkaDate != nil && b.LkaDate != nil {
+		return *(a.LkaDate) == *(b.LkaDate) && a.PTID == b.PTID
+	}
+	return false
+}
+
 // CompareLostFollowup checks if two lost_to_followup events are duplicate
 func (a lostFollowup) CompareLostFollowup(s []lostFollowup) bool {
 	for i, b := range s {
-		if a.LkaDate == nil && b.LkaDate == nil {
-			if a.PTID == b.PTID {
-				s[i].Source.Path = append(s[i].Source.Path, a.Source.Path[0])
-				return true
-			}
-		} else if a.LkaDate != nil && b.LkaDate != nil {
-			if *(a.LkaDate) == *(b.LkaDate) && a.PTID == b.PTID {
-				s[i].Source.Path = append(s[i].Source.Path, a.Source.Path[0])
-				return true
-			}
+		if a.sameAs(b) {
+			s[i].Source.add(a.Source)
+			return true
+		}
+	}
+	return false
+}
+
+// sameAs returns true if two fix events are the same event.
+func (a fix) sameAs(b fix) bool {
+	return a.PTID == b.PTID && a.EventType == b.EventType &&
+		a.Reason == b.Reason && a.Msg == b.Msg
+}
+
+// CompareFix checks if two fix events are duplicate
+func (a fix) CompareFix(s []fix) bool {
+	for i, b := range s {
+		if a.sameAs(b) {
+			s[i].Source.add(a.Source)
+			return true
 		}
 	}
 	return false
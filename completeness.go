@@ -0,0 +1,179 @@
+package excel2json
+
+import (
+	"encoding/csv"
+	"excel/helper"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the follow-up completeness of a patient up to the closing date
+type patientCompleteness struct {
+	PTID        string
+	IndexDate   string // date of the first operation
+	LastContact string // the last followup, last_known_alive or lost_to_followup date, or the date of death
+	Died        bool   // died on or before the closing date
+	Observed    int    // days of follow-up from the index date to the last contact
+	Potential   int    // days from the index date to the closing date, or to the death
+	Stale       bool   // alive and the last contact is older than the threshold
+	Workbooks   []string
+}
+
+// completeness returns the follow-up completeness of each patient with an operation on or before
+// the closing date. The follow-up is observed up to the last contact from the followup,
+// last_known_alive, lost_to_followup and death events, and potential up to the closing date,
+// or to the death if the patient died before it. A patient alive at the last contact is stale
+// if the contact is more than staleDays before the closing date. Dates after the closing date are not used.
+func completeness(closing string, staleDays int) []patientCompleteness {
+	index := map[string]string{}
+	workbooks := map[string][]string{}
+	for _, o := range allOperation {
+		if o.PTID != "" && o.Date <= closing && (index[o.PTID] == "" || o.Date < index[o.PTID]) {
+			index[o.PTID] = o.Date
+		}
+	}
+	last, died := map[string]string{}, map[string]bool{}
+	contact := func(id string, date string) {
+		if date <= closing && date > last[id] {
+			last[id] = date
+		}
+	}
+	for _, ev := range allEvents() {
+		for _, path := range ev.Source.Path {
+			workbooks[*ev.PTID] = appendNew(workbooks[*ev.PTID], path)
+		}
+		switch ev.Type {
+		case "followup", "last_known_alive":
+			contact(*ev.PTID, *ev.Date)
+		case "death":
+			if *ev.Date <= closing {
+				contact(*ev.PTID, *ev.Date)
+				died[*ev.PTID] = true
+			}
+		}
+	}
+	for _, l := range allLostFollowups {
+		if l.LkaDate != nil && *l.LkaDate != "" {
+			contact(l.PTID, *l.LkaDate)
+		} else {
+			contact(l.PTID, l.Date)
+		}
+	}
+
+	ids := []string{}
+	for id := range index {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	list := []patientCompleteness{}
+	for _, id := range ids {
+		p := patientCompleteness{PTID: id, IndexDate: index[id], LastContact: index[id], Died: died[id], Workbooks: workbooks[id]}
+		if last[id] > p.LastContact {
+			p.LastContact = last[id]
+		}
+		p.Observed = daysBetween(p.IndexDate, p.LastContact)
+		p.Potential = daysBetween(p.IndexDate, closing)
+		if p.Died {
+			p.Potential = p.Observed
+		}
+		p.Stale = !p.Died && daysBetween(p.LastContact, closing) > staleDays
+		list = append(list, p)
+	}
+	return list
+}
+
+// percent returns observed as a percentage of potential, 100 without potential follow-up.
+func percent(observed int, potential int) float64 {
+	if potential <= 0 {
+		return 100
+	}
+	return float64(observed) / float64(potential) * 100
+}
+
+// writeCompletenessText writes the completeness of all patients and of each workbook,
+// and the stale patients.
+func writeCompletenessText(w io.Writer, list []patientCompleteness, closing string, staleDays int) {
+	type total struct{ patients, observed, potential, stale int }
+	all := total{}
+	books := map[string]*total{}
+	names := []string{}
+	for _, p := range list {
+		stale := 0
+		if p.Stale {
+			stale = 1
+		}
+		all = total{all.patients + 1, all.observed + p.Observed, all.potential + p.Potential, all.stale + stale}
+		for _, b := range p.Workbooks {
+			if books[b] == nil {
+				books[b] = &total{}
+				names = append(names, b)
+			}
+			t := books[b]
+			*t = total{t.patients + 1, t.observed + p.Observed, t.potential + p.Potential, t.stale + stale}
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "Follow-up completeness up to %s: %.1f%% of %d patients, %.1f of %.1f patient-years\n",
+		closing, percent(all.observed, all.potential), all.patients, float64(all.observed)/365.25, float64(all.potential)/365.25)
+	fmt.Fprintf(w, "  %-40s %8s %12s %6s\n", "workbook", "patients", "completeness", "stale")
+	for _, b := range names {
+		t := books[b]
+		fmt.Fprintf(w, "  %-40s %8d %11.1f%% %6d\n", b, t.patients, percent(t.observed, t.potential), t.stale)
+	}
+	fmt.Fprintf(w, "\n%d patients alive at the last contact more than %d days before %s:\n", all.stale, staleDays, closing)
+	for _, p := range list {
+		if p.Stale {
+			fmt.Fprintf(w, "  %-12s last contact %s (%d days)  %s\n", p.PTID, p.LastContact,
+				daysBetween(p.LastContact, closing), strings.Join(p.Workbooks, ", "))
+		}
+	}
+}
+
+// writeCompletenessCSV writes the completeness of each patient to a CSV file.
+func writeCompletenessCSV(path string, list []patientCompleteness) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+	w.Write([]string{"patient_id", "index_date", "last_contact", "died", "observed_days", "potential_days",
+		"completeness", "stale", "workbooks"})
+	for _, p := range list {
+		w.Write([]string{p.PTID, p.IndexDate, p.LastContact, strconv.FormatBool(p.Died), strconv.Itoa(p.Observed),
+			strconv.Itoa(p.Potential), strconv.FormatFloat(percent(p.Observed, p.Potential), 'f', 1, 64),
+			strconv.FormatBool(p.Stale), strings.Join(p.Workbooks, "; ")})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// ReportCompleteness reads the events of a JSON file written by WriteToJSON and writes to w the
+// follow-up completeness (observed over potential follow-up time) up to the closing date, YYYY-MM-DD,
+// of all patients and of each workbook, and the patients whose last contact is more than staleDays
+// before the closing date. The completeness of each patient is written to csvPath if it is not empty.
+// Returns the number of stale patients.
+func ReportCompleteness(e *log.Logger, path string, closing string, staleDays int, w io.Writer, csvPath string) int {
+	_, err := time.Parse("2006-01-02", closing)
+	helper.CheckErr(e, err)
+	loadOutput(e, path)
+	list := completeness(closing, staleDays)
+	writeCompletenessText(w, list, closing, staleDays)
+	if csvPath != "" {
+		helper.CheckErr(e, writeCompletenessCSV(csvPath, list))
+	}
+	stale := 0
+	for _, p := range list {
+		if p.Stale {
+			stale++
+		}
+	}
+	return stale
+}
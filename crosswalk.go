@@ -0,0 +1,155 @@
+package excel2json
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"excel/helper"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// a patient of the identity crosswalk
+type crosswalkEntry struct {
+	PTID       string `json:"patient_id"`
+	MRN        string `json:"mrn"`
+	ResearchID string `json:"research_id"`
+}
+
+// readCrosswalk reads the crosswalk file, a JSON file (*.json) of a list of objects with
+// patient_id, mrn and research_id, or else a CSV file with a header row that has the
+// columns PTID, MRN and RESEARCH_ID.
+func readCrosswalk(path string) ([]crosswalkEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := []crosswalkEntry{}
+	if strings.HasSuffix(strings.ToLower(path), ".json") {
+		err = json.NewDecoder(file).Decode(&entries)
+		return entries, err
+	}
+
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	// the index of each column, -1 if the column is missing
+	ptid, mrn, rid := -1, -1, -1
+	for i, h := range header {
+		switch strings.ToUpper(strings.TrimSpace(h)) {
+		case "PTID", "PATIENT_ID":
+			ptid = i
+		case "MRN":
+			mrn = i
+		case "RESEARCH_ID", "RESEARCHID":
+			rid = i
+		}
+	}
+	if ptid < 0 {
+		return nil, errors.New(path + ": the crosswalk has no PTID column")
+	}
+	// value returns the value of column i of a record, "" if there is none
+	value := func(record []string, i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		entries = append(entries, crosswalkEntry{value(record, ptid), value(record, mrn), value(record, rid)})
+	}
+	return entries, nil
+}
+
+// appendNew appends s to list if it is not empty and not in the list yet.
+func appendNew(list []string, s string) []string {
+	if s == "" {
+		return list
+	}
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// applyCrosswalk reads the crosswalk file and sets the MRN and the research id of every event.
+// A PTID that is missing from the crosswalk is written to the errlog, a PTID with more than
+// one MRN or research id gets a fix message on its events, and the field is left empty.
+func applyCrosswalk(e *log.Logger, path string) {
+	entries, err := readCrosswalk(path)
+	helper.CheckErr(e, err)
+	mrns, rids := map[string][]string{}, map[string][]string{}
+	for _, c := range entries {
+		id := strings.TrimSpace(c.PTID)
+		mrns[id] = appendNew(mrns[id], strings.TrimSpace(c.MRN))
+		rids[id] = appendNew(rids[id], strings.TrimSpace(c.ResearchID))
+	}
+
+	missing := map[string]bool{}
+	for _, ev := range allEvents() {
+		id := strings.TrimSpace(*ev.PTID)
+		if id == "" {
+			continue
+		}
+		if _, ok := mrns[id]; !ok {
+			missing[id] = true
+			continue
+		}
+		if len(mrns[id]) == 1 {
+			*ev.MRN = mrns[id][0]
+		} else if len(mrns[id]) > 1 {
+			*ev.Fix = append(*ev.Fix, errMessage{"mrn", "PTID maps to multiple MRNs: '" + strings.Join(mrns[id], "', '") + "'"})
+		}
+		if len(rids[id]) == 1 {
+			*ev.ResearchID = rids[id][0]
+		} else if len(rids[id]) > 1 {
+			*ev.Fix = append(*ev.Fix, errMessage{"research_id", "PTID maps to multiple research ids: '" + strings.Join(rids[id], "', '") + "'"})
+		}
+	}
+	for i := range allFix {
+		f := &allFix[i]
+		id := strings.TrimSpace(f.PTID)
+		if id == "" {
+			continue
+		}
+		if _, ok := mrns[id]; !ok {
+			missing[id] = true
+			continue
+		}
+		if len(mrns[id]) == 1 {
+			f.MRN = mrns[id][0]
+		}
+		if len(rids[id]) == 1 {
+			f.ResearchID = rids[id][0]
+		}
+	}
+
+	ids := []string{}
+	for id := range missing {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		e.Println(path, "INFO: PTID is missing from the crosswalk:", id)
+	}
+	if len(ids) > 0 {
+		fmt.Println(len(ids), "PTIDs are missing from the crosswalk, see the errorlog")
+	}
+}
@@ -0,0 +1,183 @@
+package excel2json
+
+import (
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// an issue found in a row, either a fix event or a fix message of an event
+type issue struct {
+	Row   rowRef
+	Link  string // link to the excel file
+	Type  string // the type of the event
+	Field string // the reason of a fix event or the field of a fix message
+	Msg   string
+}
+
+// a name and a count, used for the tables of the dashboard
+type count struct {
+	Name  string
+	Count int
+}
+
+// the data of the dashboard
+type dashboard struct {
+	Events     []count            // counts per event type
+	Reasons    []string           // the reasons of the fix events found
+	FixReasons map[string][]count // counts of fix reasons per workbook
+	Columns    []count            // columns that need fixing
+	Workbooks  []workbook
+	Issues     []issue
+}
+
+// fileLink returns a link to the excel file of the sub path.
+func fileLink(path string) string {
+	abs, err := filepath.Abs(filepath.Join(opts.Root, filepath.FromSlash(path)))
+	if err != nil {
+		return path
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}
+	return u.String()
+}
+
+// sortCounts returns the counts of a map sorted by count, then by name.
+func sortCounts(m map[string]int) []count {
+	counts := []count{}
+	for name, n := range m {
+		counts = append(counts, count{name, n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
+
+// newDashboard collects the data of the dashboard from the events and workbooks.
+func newDashboard() dashboard {
+	d := dashboard{FixReasons: map[string][]count{}, Workbooks: allWorkbooks}
+	events := map[string]int{}
+	columns := map[string]int{}
+	// fix reasons per workbook
+	reasons := map[string]map[string]int{}
+	for _, w := range allWorkbooks {
+		reasons[w.Path] = map[string]int{}
+	}
+
+	for _, ev := range allEvents() {
+		events[ev.Type]++
+		for _, msg := range *ev.Fix {
+			for _, r := range ev.Source.Rows {
+				d.Issues = append(d.Issues, issue{r, fileLink(r.Path), ev.Type, msg.Field, msg.Msg})
+			}
+		}
+	}
+	for _, f := range allFix {
+		events[f.Type]++
+		columns[f.Column]++
+		for _, r := range f.Source.Rows {
+			if reasons[r.Path] == nil {
+				reasons[r.Path] = map[string]int{}
+			}
+			reasons[r.Path][f.Reason]++
+			d.Issues = append(d.Issues, issue{r, fileLink(r.Path), f.EventType, f.Reason, f.Msg})
+		}
+	}
+
+	d.Events = sortCounts(events)
+	d.Columns = sortCounts(columns)
+	// the reasons of the fix events that were found, in the order of the taxonomy
+	for _, reason := range []string{fixMissingDate, fixInvalidDate, fixInvalidCode, fixConflictingSources, fixRule} {
+		for _, m := range reasons {
+			if m[reason] > 0 {
+				d.Reasons = append(d.Reasons, reason)
+				break
+			}
+		}
+	}
+	for path, m := range reasons {
+		for _, reason := range d.Reasons {
+			d.FixReasons[path] = append(d.FixReasons[path], count{reason, m[reason]})
+		}
+	}
+	// issues in the order of the excel files
+	sort.SliceStable(d.Issues, func(i, j int) bool {
+		a, b := d.Issues[i].Row, d.Issues[j].Row
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Sheet != b.Sheet {
+			return a.Sheet < b.Sheet
+		}
+		return a.Row < b.Row
+	})
+	return d
+}
+
+// writeDashboard writes a self-contained HTML data-quality report of the run to the file of htmlPath.
+func writeDashboard(htmlPath string) error {
+	file, err := os.Create(htmlPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return dashboardTemplate.Execute(file, newDashboard())
+}
+
+// the template of the HTML report, with the styles inline so the file can be shared on its own
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Data-quality report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #eee; }
+td.n { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Data-quality report</h1>
+
+<h2>Events per type</h2>
+<table>
+<tr><th>Type</th><th>Events</th></tr>
+{{range .Events}}<tr><td>{{.Name}}</td><td class="n">{{.Count}}</td></tr>
+{{end}}</table>
+
+<h2>Fix reasons per workbook</h2>
+<table>
+<tr><th>Workbook</th>{{range .Reasons}}<th>{{.}}</th>{{end}}</tr>
+{{range $path, $counts := .FixReasons}}<tr><td>{{$path}}</td>{{range $counts}}<td class="n">{{.Count}}</td>{{end}}</tr>
+{{end}}</table>
+
+<h2>Top offending columns</h2>
+<table>
+<tr><th>Column</th><th>Fix events</th></tr>
+{{range .Columns}}<tr><td>{{.Name}}</td><td class="n">{{.Count}}</td></tr>
+{{end}}</table>
+
+<h2>Workbooks</h2>
+<table>
+<tr><th>Workbook</th><th>Rows</th><th>Not follow_up sheets</th><th>Unexpected columns</th></tr>
+{{range .Workbooks}}<tr><td>{{.Path}}</td><td class="n">{{.Rows}}</td>
+<td>{{range .Skipped}}oops! this is not a follow_up sheet: #{{.Sheet}} {{.SheetName}}<br>{{end}}</td>
+<td>{{range .Unexpected}}{{.}}<br>{{end}}</td></tr>
+{{end}}</table>
+
+<h2>Issues</h2>
+<table>
+<tr><th>File</th><th>Sheet</th><th>Row</th><th>Event</th><th>Field or reason</th><th>Message</th></tr>
+{{range .Issues}}<tr><td><a href="{{.Link}}">{{.Row.Path}}</a></td><td>#{{.Row.Sheet}} {{.Row.SheetName}}</td>
+<td class="n">{{.Row.Row}}</td><td>{{.Type}}</td><td>{{.Field}}</td><td>{{.Msg}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,310 @@
+package excel2json
+
+import (
+	"encoding/json"
+	"excel/helper"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// an event read from a JSON file written by WriteToJSON
+type outputEvent struct {
+	Type  string
+	PTID  string
+	Date  string
+	Value interface{} // the event, one of the event types
+}
+
+// a field of an event that has a different value in the new output
+type fieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// an event of the old output that has different values in the new output
+type eventChange struct {
+	Type    string        `json:"type"`
+	OldDate string        `json:"old_date"`
+	NewDate string        `json:"new_date"`
+	Fields  []fieldChange `json:"fields"`
+}
+
+// the differences of the events of a patient
+type patientDiff struct {
+	PTID    string        `json:"patient_id"`
+	Added   []interface{} `json:"added"`
+	Removed []interface{} `json:"removed"`
+	Changed []eventChange `json:"changed"`
+}
+
+// readOutput reads the events of a JSON file written by WriteToJSON.
+func readOutput(e *log.Logger, path string) []outputEvent {
+	file, err := os.Open(path)
+	helper.CheckErr(e, err)
+	defer file.Close()
+
+	list := []outputEvent{}
+	// the JSON objects are written one after another
+	dec := json.NewDecoder(file)
+	for {
+		var raw json.RawMessage
+		err := dec.Decode(&raw)
+		if err == io.EOF {
+			break
+		}
+		helper.CheckErr(e, err)
+		// get the type first, then decode to the type of event
+		head := struct {
+			Type string `json:"type"`
+		}{}
+		helper.CheckErr(e, json.Unmarshal(raw, &head))
+
+		var o outputEvent
+		switch head.Type {
+		case "followup", "last_known_alive":
+			v := followups{}
+			helper.CheckErr(e, json.Unmarshal(raw, &v))
+			o = outputEvent{v.Type, v.PTID, v.Date, v}
+		case "death":
+			v := death{}
+			helper.CheckErr(e, json.Unmarshal(raw, &v))
+			o = outputEvent{v.Type, v.PTID, v.Date, v}
+		case "stroke", "tia":
+			v := te{}
+			helper.CheckErr(e, json.Unmarshal(raw, &v))
+			o = outputEvent{v.Type, v.PTID, v.Date, v}
+		case "lost_to_followup":
+			v := lostFollowup{}
+			helper.CheckErr(e, json.Unmarshal(raw, &v))
+			o = outputEvent{v.Type, v.PTID, v.Date, v}
+		case "operation":
+			v := operation{}
+			helper.CheckErr(e, json.Unmarshal(raw, &v))
+			o = outputEvent{v.Type, v.PTID, v.Date, v}
+		case "fix":
+			v := fix{}
+			helper.CheckErr(e, json.Unmarshal(raw, &v))
+			o = outputEvent{v.Type, v.PTID, "", v}
+			if v.Date != nil {
+				o.Date = *v.Date
+			}
+		default:
+			v := general{}
+			helper.CheckErr(e, json.Unmarshal(raw, &v))
+			o = outputEvent{v.Type, v.PTID, v.Date, v}
+		}
+		list = append(list, o)
+	}
+	return list
+}
+
+// sameEvent returns true if a and b are the same event,
+// using the same rules as the Compare methods.
+func sameEvent(a outputEvent, b outputEvent) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	switch v := a.Value.(type) {
+	case followups:
+		return v.sameAs(b.Value.(followups))
+	case death:
+		return v.sameAs(b.Value.(death))
+	case te:
+		return v.sameAs(b.Value.(te))
+	case lostFollowup:
+		return v.sameAs(b.Value.(lostFollowup))
+	case operation:
+		return v.sameAs(b.Value.(operation))
+	case fix:
+		return v.sameAs(b.Value.(fix))
+	case general:
+		return v.sameAs(b.Value.(general))
+	}
+	return false
+}
+
+// fieldChanges returns the fields that have different values in a and b.
+// The source and the fix messages are not compared.
+func fieldChanges(a interface{}, b interface{}) []fieldChange {
+	var ma, mb map[string]interface{}
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	json.Unmarshal(ja, &ma)
+	json.Unmarshal(jb, &mb)
+
+	fields := []string{}
+	for k := range ma {
+		fields = append(fields, k)
+	}
+	for k := range mb {
+		if _, ok := ma[k]; !ok {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := []fieldChange{}
+	for _, k := range fields {
+		if k == "source" || k == "fix" {
+			continue
+		}
+		if !reflect.DeepEqual(ma[k], mb[k]) {
+			changes = append(changes, fieldChange{k, ma[k], mb[k]})
+		}
+	}
+	return changes
+}
+
+// diffOutputs returns the differences between two lists of events, per patient.
+// Events of the same type and patient are matched first by the rules of the Compare methods,
+// then the events left are paired if they have the same date, and at last if one event
+// is left in each list (such as a corrected death date).
+func diffOutputs(oldList []outputEvent, newList []outputEvent) []patientDiff {
+	// group the events by type and patient
+	oldGroups, newGroups := map[string][]int{}, map[string][]int{}
+	keys := []string{}
+	for i, o := range oldList {
+		k := o.Type + "\x00" + o.PTID
+		if oldGroups[k] == nil {
+			keys = append(keys, k)
+		}
+		oldGroups[k] = append(oldGroups[k], i)
+	}
+	for j, o := range newList {
+		k := o.Type + "\x00" + o.PTID
+		if oldGroups[k] == nil && newGroups[k] == nil {
+			keys = append(keys, k)
+		}
+		newGroups[k] = append(newGroups[k], j)
+	}
+
+	diffs := map[string]*patientDiff{}
+	patient := func(id string) *patientDiff {
+		if diffs[id] == nil {
+			diffs[id] = &patientDiff{PTID: id, Added: []interface{}{}, Removed: []interface{}{}, Changed: []eventChange{}}
+		}
+		return diffs[id]
+	}
+
+	for _, k := range keys {
+		olds, news := oldGroups[k], newGroups[k]
+		oldUsed, newUsed := make([]bool, len(olds)), make([]bool, len(news))
+		// pair the i-th old event and the j-th new event of the group
+		pair := func(i int, j int) {
+			oldUsed[i], newUsed[j] = true, true
+			a, b := oldList[olds[i]], newList[news[j]]
+			if fields := fieldChanges(a.Value, b.Value); len(fields) > 0 {
+				p := patient(a.PTID)
+				p.Changed = append(p.Changed, eventChange{a.Type, a.Date, b.Date, fields})
+			}
+		}
+		// the same event
+		for i := range olds {
+			for j := range news {
+				if !newUsed[j] && sameEvent(oldList[olds[i]], newList[news[j]]) {
+					pair(i, j)
+					break
+				}
+			}
+		}
+		// the same date
+		for i := range olds {
+			for j := range news {
+				if !oldUsed[i] && !newUsed[j] && oldList[olds[i]].Date == newList[news[j]].Date {
+					pair(i, j)
+					break
+				}
+			}
+		}
+		// the only events left
+		oldLeft, newLeft := []int{}, []int{}
+		for i := range olds {
+			if !oldUsed[i] {
+				oldLeft = append(oldLeft, i)
+			}
+		}
+		for j := range news {
+			if !newUsed[j] {
+				newLeft = append(newLeft, j)
+			}
+		}
+		if len(oldLeft) == 1 && len(newLeft) == 1 {
+			pair(oldLeft[0], newLeft[0])
+		}
+		// the rest are removed or added
+		for i := range olds {
+			if !oldUsed[i] {
+				p := patient(oldList[olds[i]].PTID)
+				p.Removed = append(p.Removed, oldList[olds[i]].Value)
+			}
+		}
+		for j := range news {
+			if !newUsed[j] {
+				p := patient(newList[news[j]].PTID)
+				p.Added = append(p.Added, newList[news[j]].Value)
+			}
+		}
+	}
+
+	list := []patientDiff{}
+	for _, p := range diffs {
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].PTID < list[j].PTID })
+	return list
+}
+
+// describe returns a short text of an event: the type and the date.
+func describe(v interface{}) string {
+	ja, _ := json.Marshal(v)
+	head := struct {
+		Type string  `json:"type"`
+		Date *string `json:"date"`
+	}{}
+	json.Unmarshal(ja, &head)
+	if head.Date == nil {
+		return head.Type + " (no date)"
+	}
+	return head.Type + " " + *head.Date
+}
+
+// writeDiffText writes the differences as readable text to w.
+func writeDiffText(w io.Writer, diffs []patientDiff) {
+	var added, removed, changed int
+	for _, p := range diffs {
+		fmt.Fprintln(w, "patient:", p.PTID)
+		for _, v := range p.Added {
+			fmt.Fprintln(w, "  + added:  ", describe(v))
+		}
+		for _, v := range p.Removed {
+			fmt.Fprintln(w, "  - removed:", describe(v))
+		}
+		for _, c := range p.Changed {
+			fmt.Fprintln(w, "  * changed:", c.Type, c.OldDate)
+			for _, f := range c.Fields {
+				fmt.Fprintf(w, "      %s: %v -> %v\n", f.Field, f.Old, f.New)
+			}
+		}
+		added += len(p.Added)
+		removed += len(p.Removed)
+		changed += len(p.Changed)
+	}
+	fmt.Fprintln(w, "patients:", len(diffs), "added:", added, "removed:", removed, "changed:", changed)
+}
+
+// DiffFiles compares two JSON files written by WriteToJSON, it writes the added, removed
+// and changed events per patient as readable text to standard output, and as JSON to
+// jsonFile if it is not nil. Returns the number of patients with differences.
+func DiffFiles(e *log.Logger, oldPath string, newPath string, jsonFile *os.File) int {
+	diffs := diffOutputs(readOutput(e, oldPath), readOutput(e, newPath))
+	writeDiffText(os.Stdout, diffs)
+	if jsonFile != nil {
+		helper.WriteTOFile(jsonFile, diffs)
+	}
+	return len(diffs)
+}
@@ -0,0 +1,177 @@
+package excel2json
+
+// eventRef refers to an event of any type, except fix events,
+// through the fields that all of these events share.
+type eventRef struct {
+	Type       string
+	PTID       *string
+	MRN        *string
+	ResearchID *string
+	Date       *string
+	DateEst    *int
+	Fix        *[]errMessage
+	Source     *source
+	AgeAtEvent **int
+}
+
+// allEvents returns a reference to every event stored in the slices, except fix events.
+func allEvents() []eventRef {
+	refs := []eventRef{}
+	for i := range allFollowUps {
+		o := &allFollowUps[i]
+		refs = append(refs, eventRef{o.Type, &o.PTID, &o.MRN, &o.ResearchID, &o.Date, &o.DateEst, &o.Fix, &o.Source, &o.AgeAtEvent})
+	}
+	for i := range allLKA {
+		o := &allLKA[i]
+		refs = append(refs, eventRef{o.Type, &o.PTID, &o.MRN, &o.ResearchID, &o.Date, &o.DateEst, &o.Fix, &o.Source, &o.AgeAtEvent})
+	}
+	for i := range allDths {
+		o := &allDths[i]
+		refs = append(refs, eventRef{o.Type, &o.PTID, &o.MRN, &o.ResearchID, &o.Date, &o.DateEst, &o.Fix, &o.Source, &o.AgeAtEvent})
+	}
+	for i := range allOperation {
+		o := &allOperation[i]
+		refs = append(refs, eventRef{o.Type, &o.PTID, &o.MRN, &o.ResearchID, &o.Date, &o.DateEst, &o.Fix, &o.Source, &o.AgeAtEvent})
+	}
+	for i := range allLostFollowups {
+		o := &allLostFollowups[i]
+		refs = append(refs, eventRef{o.Type, &o.PTID, &o.MRN, &o.ResearchID, &o.Date, &o.DateEst, &o.Fix, &o.Source, &o.AgeAtEvent})
+	}
+	// stroke and tia events
+	for _, s := range [][]te{allStroke, allTIA} {
+		for i := range s {
+			o := &s[i]
+			refs = append(refs, eventRef{o.Type, &o.PTID, &o.MRN, &o.ResearchID, &o.Date, &o.DateEst, &o.Fix, &o.Source, &o.AgeAtEvent})
+		}
+	}
+	// events of the type general
+	for _, s := range [][]general{allSBE, allARH, allFUMI, allFUPACE, allSVD, allPVL, allDVT, allTHRM, alllHEML} {
+		for i := range s {
+			o := &s[i]
+			refs = append(refs, eventRef{o.Type, &o.PTID, &o.MRN, &o.ResearchID, &o.Date, &o.DateEst, &o.Fix, &o.Source, &o.AgeAtEvent})
+		}
+	}
+	return refs
+}
+
+// fromFile returns true if one of the rows of the source is from the excel file of the sub path.
+func (s source) fromFile(path string) bool {
+	for _, r := range s.Rows {
+		if r.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// events holds the slices of all types of events, used to keep
+// the events of a workbook apart from the events of the other workbooks.
+type events struct {
+	FollowUps     []followups    `json:"followups"`
+	Dths          []death        `json:"death"`
+	TIA           []te           `json:"tia"`
+	Stroke        []te           `json:"stroke"`
+	SBE           []general      `json:"sbe"`
+	ARH           []general      `json:"arh"`
+	LostFollowups []lostFollowup `json:"lost_to_followup"`
+	Operation     []operation    `json:"operation"`
+	FUMI          []general      `json:"myocardial_infarction"`
+	FUPACE        []general      `json:"perm_pacemaker"`
+	SVD           []general      `json:"struct_valve_det"`
+	PVL           []general      `json:"perivalvular_leak"`
+	DVT           []general      `json:"deep_vein_thrombosis"`
+	THRM          []general      `json:"thromb_prost_valve"`
+	HEML          []general      `json:"hemolysis_dx"`
+	LKA           []followups    `json:"last_known_alive"`
+	Fix           []fix          `json:"fix"`
+	IndexOps      []operation    `json:"index_operations"`
+}
+
+// takeEvents returns the events stored in the slices and empties the slices.
+func takeEvents() events {
+	ev := events{allFollowUps, allDths, allTIA, allStroke, allSBE, allARH, allLostFollowups,
+		allOperation, allFUMI, allFUPACE, allSVD, allPVL, allDVT, allTHRM, alllHEML, allLKA, allFix, allIndexOps}
+	allFollowUps, allDths, allTIA, allStroke, allSBE, allARH, allLostFollowups = nil, nil, nil, nil, nil, nil, nil
+	allOperation, allFUMI, allFUPACE, allSVD, allPVL, allDVT, allTHRM, alllHEML, allLKA, allFix = nil, nil, nil, nil, nil, nil, nil, nil, nil, nil
+	allIndexOps = nil
+	return ev
+}
+
+// putEvents stores ev in the slices, replacing the events that are there.
+func putEvents(ev events) {
+	allFollowUps, allDths, allTIA, allStroke, allSBE, allARH, allLostFollowups = ev.FollowUps, ev.Dths, ev.TIA,
+		ev.Stroke, ev.SBE, ev.ARH, ev.LostFollowups
+	allOperation, allFUMI, allFUPACE, allSVD, allPVL, allDVT, allTHRM, alllHEML, allLKA, allFix = ev.Operation, ev.FUMI,
+		ev.FUPACE, ev.SVD, ev.PVL, ev.DVT, ev.THRM, ev.HEML, ev.LKA, ev.Fix
+	allIndexOps = ev.IndexOps
+}
+
+// merge adds the events of ev to the slices, checking for duplicates
+// the same way as when the events are read from the excel files.
+func (ev events) merge() {
+	for _, o := range ev.FollowUps {
+		if !o.CompareFollowups(allFollowUps) {
+			allFollowUps = append(allFollowUps, o)
+		}
+	}
+	for _, o := range ev.LKA {
+		if !o.CompareFollowups(allLKA) {
+			allLKA = append(allLKA, o)
+		}
+	}
+	for _, o := range ev.Dths {
+		d := o
+		if !(&d).CompareDeath(&allDths) {
+			allDths = append(allDths, d)
+		}
+	}
+	for _, o := range ev.Operation {
+		if !o.CompareOperation(allOperation) {
+			allOperation = append(allOperation, o)
+		}
+	}
+	for _, o := range ev.IndexOps {
+		if !o.CompareOperation(allIndexOps) {
+			allIndexOps = append(allIndexOps, o)
+		}
+	}
+	for _, o := range ev.LostFollowups {
+		if !o.CompareLostFollowup(allLostFollowups) {
+			allLostFollowups = append(allLostFollowups, o)
+		}
+	}
+	for _, o := range ev.Stroke {
+		if !o.CompareTE(allStroke) {
+			allStroke = append(allStroke, o)
+		}
+	}
+	for _, o := range ev.TIA {
+		if !o.CompareTE(allTIA) {
+			allTIA = append(allTIA, o)
+		}
+	}
+	// events of the type general
+	mergeGeneral(ev.SBE, &allSBE)
+	mergeGeneral(ev.ARH, &allARH)
+	mergeGeneral(ev.FUMI, &allFUMI)
+	mergeGeneral(ev.FUPACE, &allFUPACE)
+	mergeGeneral(ev.SVD, &allSVD)
+	mergeGeneral(ev.PVL, &allPVL)
+	mergeGeneral(ev.DVT, &allDVT)
+	mergeGeneral(ev.THRM, &allTHRM)
+	mergeGeneral(ev.HEML, &alllHEML)
+	for _, o := range ev.Fix {
+		if !o.CompareFix(allFix) {
+			allFix = append(allFix, o)
+		}
+	}
+}
+
+// mergeGeneral adds the events of s to the slice all, checking for duplicates.
+func mergeGeneral(s []general, all *[]general) {
+	for _, o := range s {
+		if !o.CompareEvents(*all) {
+			*all = append(*all, o)
+		}
+	}
+}
@@ -14,7 +14,96 @@ import (
 // LoopAllFiles recursively loops all files in a folder, and tracks all excel files,
 // opens a errorlog and a json file to store error messages and json objects, and
 // for each excel file, calls another function to read data from the file.
-func LoopAllFiles(e *log.Logger, dirPath string, jsonFile *os.File) {
+// If o.ValidateOnly is true, jsonFile is not used and a data-quality scorecard
+// of each excel file is printed instead.
+// o holds the options of the run, if o.Root is empty, paths are relative to dirPath.
+func LoopAllFiles(e *log.Logger, dirPath string, jsonFile *os.File, o Options) {
+	opts = o
+	if opts.Root == "" {
+		opts.Root = dirPath
+	}
+	fileList := excelFiles(dirPath)
+	loadSheetClasses(e)
+	loadTables(e)
+	// get the valid column names, ask for the columns file if it is not set
+	columnsChecker := opts.Columns
+	if columnsChecker == "" {
+		columnsChecker = helper.ReadUserInput()
+	}
+	// Loop through all excel files,
+	// only read the changed ones if there is a state file
+	if opts.StatePath != "" {
+		readIncremental(e, fileList, jsonFile, columnsChecker)
+	} else {
+		for _, file := range fileList {
+			ReadExcelData(e, file, jsonFile, columnsChecker)
+		}
+	}
+	// set the MRN and the research id of the events
+	if opts.Crosswalk != "" {
+		applyCrosswalk(e, opts.Crosswalk)
+	}
+	// set the age at each event from the birth date in the PTID
+	setAges()
+	// link the re-operations to the operations they followed
+	linkOperations()
+	// classify the morbidity events as early or late after the nearest preceding operation
+	helper.CheckErr(e, setWhen())
+	// decide from the dates of the operations if the deaths were operative
+	setOperative()
+	// report the PTIDs that are likely typos of each other
+	if opts.Duplicates != "" {
+		helper.CheckErr(e, writeDuplicates(opts.Duplicates, findDuplicates()))
+	}
+
+	// write the HTML data-quality report if asked
+	if opts.HTMLPath != "" {
+		helper.CheckErr(e, writeDashboard(opts.HTMLPath))
+	}
+
+	// write the survival dataset if asked
+	if opts.Survival != "" {
+		helper.CheckErr(e, writeSurvival(e, opts.Survival))
+	}
+
+	// only report the data quality when validating
+	if opts.ValidateOnly {
+		writeScorecards(os.Stdout)
+		return
+	}
+
+	// write to JSON file
+	WriteToJSON(jsonFile, allARH, allDVT, allDths, allFUMI, allFUPACE, allFix, allFollowUps,
+		allLKA, allOperation, allPVL, allSBE, allSVD,
+		allStroke, allTHRM, allTIA, alllHEML, allLostFollowups)
+}
+
+// rules that detect the type of the sheets
+var sheetClasses []helper.SheetClass
+
+// loadTables reads the aliases, the procedure vocabulary, the organism table, the rules and the
+// status precedence of opts, that the rows are read with.
+func loadTables(e *log.Logger) {
+	loadAliases(e)
+	loadProcedures(e)
+	loadOrganisms(e)
+	loadRules(e)
+	loadPrecedence(e)
+}
+
+// loadSheetClasses reads the sheet classes from the file of opts.Sheets,
+// or uses the follow_up rules if it is empty.
+func loadSheetClasses(e *log.Logger) {
+	sheetClasses = helper.DefaultSheetClasses
+	if opts.Sheets != "" {
+		classes, err := helper.ReadSheetClasses(opts.Sheets)
+		helper.CheckErr(e, err)
+		sheetClasses = classes
+	}
+}
+
+// excelFiles recursively loops all files in a folder and returns the excel files.
+func excelFiles(dirPath string) []string {
 	fileList := []string{}
 	filepath.Walk(dirPath, func(path string, f os.FileInfo, err error) error {
 		if err != nil {
@@ -24,42 +113,45 @@ func LoopAllFiles(e *log.Logger, dirPath string, jsonFile *os.File) {
 		}
 		return nil
 	})
-	// get the valid column names
-	columnsChecker := helper.ReadUserInput()
-	// Loop through all excel files
-	for _, file := range fileList {
-		ReadExcelData(e, file, jsonFile, columnsChecker)
-	}
-
-	// write to JSON file
-	WriteToJSON(jsonFile, allARH, allDVT, allDths, allFUMI, allFUPACE, allFix, allFollowUps,
-		allLKA, allOperation, allPVL, allSBE, allSVD,
-		allStroke, allTHRM, allTIA, alllHEML, allLostFollowups)
+	return fileList
 }
 
-// ExcelToSlice returns a slice of slices of maps for one excel file.
-// (Assume a excel file may contain multiple sheets)
-// Each row of a sheet is restructed to a map, then appended to a slice,
-// and each sheet is restructed to a slice containing list of maps.
-func ExcelToSlice(e *log.Logger, excelFilePath string, columnsChecker string) ([][]map[string]string, [][]string) {
-
+// openWorkbook opens an excel file, and saves it first if its header row cannot be read.
+func openWorkbook(e *log.Logger, excelFilePath string) *xlsx.File {
 	// Check if the file has a header row that cannot be read due to some reasons
 	unreadable, xlFile := helper.CheckHeaderRow(e, excelFilePath)
 	// if the excel file has a header row that cannot be read
 	if unreadable {
 		xlFile, _ = xlsx.OpenFile(excelFilePath)
 	}
+	return xlFile
+}
+
+// ExcelToSlice returns a slice of slices of maps for one excel file.
+// (Assume a excel file may contain multiple sheets)
+// Each row of a sheet is restructed to a map, then appended to a slice,
+// and each sheet is restructed to a slice containing list of maps.
+// The names of the sheets, their types and the unexpected column names are returned as well.
+// Only the sheets that match one of the sheet classes are read, the others are nil.
+func ExcelToSlice(e *log.Logger, excelFilePath string, columnsChecker string) ([][]map[string]string, [][]string, []string, []string, []string) {
+
+	xlFile := openWorkbook(e, excelFilePath)
 	slices := [][]map[string]string{}
 	keyList := [][]string{}
+	names := []string{}
+	types := []string{}
+	unexpected := []string{}
 	// s is the index of Sheets
 	for s, sheet := range xlFile.Sheets {
-		// check to see if a sheet is a followup sheet
-		isFu, keys := helper.CheckFollowups(e, excelFilePath, s, sheet)
+		names = append(names, sheet.Name)
+		// check the type of the sheet
+		sheetType, keys := helper.CheckSheetType(e, excelFilePath, s, sheet, sheetClasses)
+		types = append(types, sheetType)
 
-		// if the sheet is a followup sheet
-		if isFu {
+		// if the sheet has a type
+		if sheetType != "" {
 			// check if columnn names are the expected ones
-			helper.CheckColumnNames(columnsChecker, e, keys, excelFilePath, s)
+			unexpected = append(unexpected, helper.CheckColumnNames(columnsChecker, e, keys, excelFilePath, s)...)
 
 			keyList = append(keyList, keys)
 			slice := []map[string]string{} // a sheet is a slice
@@ -83,11 +175,11 @@ func ExcelToSlice(e *log.Logger, excelFilePath string, columnsChecker string) ([
 				slice = append(slice, m)
 			}
 			slices = append(slices, slice[1:])
-			// else if the sheet is not a followup sheet
+			// else if the sheet has no type
 		} else {
 			slices = append(slices, nil)
 			keyList = append(keyList, nil)
 		}
 	}
-	return slices, keyList
+	return slices, keyList, names, types, unexpected
 }
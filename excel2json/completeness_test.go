@@ -0,0 +1,57 @@
+package excel2json
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestCompletenessOne
+func TestCompletenessOne(t *testing.T) {
+	t.Log("Test for completeness - the last contact up to the closing date, lost_to_followup, deaths and stale patients")
+	reset()
+	lka := "2007-01-01"
+	allOperation = []operation{
+		{Type: "operation", PTID: "ABCD092780", Date: "2005-01-01"},
+		{Type: "operation", PTID: "EFGH010180", Date: "2005-01-01"},
+		{Type: "operation", PTID: "IJKL020280", Date: "2005-01-01"},
+		{Type: "operation", PTID: "MNOP030380", Date: "2011-01-01"},
+		{Type: "operation", PTID: "QRST040480", Date: "2005-01-01"},
+	}
+	allFollowUps = []followups{
+		{Type: "followup", PTID: "ABCD092780", Date: "2009-06-01", Source: source{Path: []string{"fu.xlsx"}}},
+		{Type: "followup", PTID: "ABCD092780", Date: "2011-01-01"},
+	}
+	allLostFollowups = []lostFollowup{{Type: "lost_to_followup", PTID: "EFGH010180", Date: "2009-01-01", LkaDate: &lka}}
+	allDths = []death{
+		{Type: "death", PTID: "IJKL020280", Date: "2008-01-01"},
+		{Type: "death", PTID: "QRST040480", Date: "2011-01-01"},
+	}
+	list := completeness("2010-01-01", 365)
+	want := []patientCompleteness{
+		// the followup after the closing date is not used
+		{PTID: "ABCD092780", LastContact: "2009-06-01", Observed: 1612, Potential: 1826},
+		// the last known alive date of the lost_to_followup event
+		{PTID: "EFGH010180", LastContact: "2007-01-01", Observed: 730, Potential: 1826, Stale: true},
+		// the potential follow-up ends at the death
+		{PTID: "IJKL020280", LastContact: "2008-01-01", Died: true, Observed: 1095, Potential: 1095},
+		// the death after the closing date is not used
+		{PTID: "QRST040480", LastContact: "2005-01-01", Observed: 0, Potential: 1826, Stale: true},
+	}
+	if len(list) != len(want) {
+		t.Fatal("Expected:", len(want), "patients, got:", list)
+	}
+	for i, w := range want {
+		p := list[i]
+		if p.PTID != w.PTID || p.IndexDate != "2005-01-01" || p.LastContact != w.LastContact || p.Died != w.Died ||
+			p.Observed != w.Observed || p.Potential != w.Potential || p.Stale != w.Stale {
+			t.Error("Expected:", w, "got:", p)
+		}
+	}
+
+	var out bytes.Buffer
+	writeCompletenessText(&out, list, "2010-01-01", 365)
+	if !strings.Contains(out.String(), "2 patients alive at the last contact more than 365 days before 2010-01-01") {
+		t.Error("Expected:", "2 stale patients", "got:", out.String())
+	}
+}
@@ -0,0 +1,75 @@
+package excel2json
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestApplyCrosswalkOne
+func TestApplyCrosswalkOne(t *testing.T) {
+	t.Log("Test for applyCrosswalk - the CSV and the JSON crosswalks, PTIDs with two MRNs and PTIDs missing from the crosswalk")
+	dir := t.TempDir()
+	files := map[string]string{
+		"crosswalk.csv": "Note, ptid ,MRN,Research_ID\n" +
+			"x,ABCD092780,111,R1\n" +
+			"x,EFGH010180,222,R2\n" +
+			"x,EFGH010180,223,R2\n",
+		"crosswalk.json": `[{"patient_id": "ABCD092780", "mrn": "111", "research_id": "R1"},
+			{"patient_id": "EFGH010180", "mrn": "222", "research_id": "R2"},
+			{"patient_id": "EFGH010180", "mrn": "223", "research_id": " R2 "}]`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		reset()
+		allFollowUps = []followups{
+			{Type: "followup", PTID: "ABCD092780", Date: "2010-01-01"},
+			{Type: "followup", PTID: "EFGH010180", Date: "2010-01-01"},
+			{Type: "followup", PTID: "WXYZ010101", Date: "2010-01-01"},
+		}
+		allFix = []fix{{Type: "fix", PTID: "ABCD092780", Reason: fixMissingDate}}
+		var errlog bytes.Buffer
+		applyCrosswalk(log.New(&errlog, "ERROR: ", 0), path)
+
+		if a := allFollowUps[0]; a.MRN != "111" || a.ResearchID != "R1" || len(a.Fix) != 0 {
+			t.Error("Expected:", "111 R1", "got:", a.MRN, a.ResearchID, a.Fix, "for", name)
+		}
+		if f := allFix[0]; f.MRN != "111" || f.ResearchID != "R1" {
+			t.Error("Expected:", "the fix event with 111 R1", "got:", f.MRN, f.ResearchID, "for", name)
+		}
+		b := allFollowUps[1]
+		if b.MRN != "" || b.ResearchID != "R2" || len(b.Fix) != 1 || b.Fix[0].Msg != "PTID maps to multiple MRNs: '222', '223'" {
+			t.Error("Expected:", "no MRN and a fix message", "got:", b.MRN, b.ResearchID, b.Fix, "for", name)
+		}
+		if c := allFollowUps[2]; c.MRN != "" || !strings.Contains(errlog.String(), "PTID is missing from the crosswalk: WXYZ010101") ||
+			strings.Count(errlog.String(), "missing") != 1 {
+			t.Error("Expected:", "WXYZ010101 missing from the crosswalk", "got:", errlog.String(), "for", name)
+		}
+	}
+}
+
+// TestReadCrosswalkOne
+func TestReadCrosswalkOne(t *testing.T) {
+	t.Log("Test for readCrosswalk - a CSV crosswalk without PTID column and short records")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "crosswalk.csv")
+	if err := ioutil.WriteFile(path, []byte("MRN,RESEARCH_ID\n111,R1\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readCrosswalk(path); err == nil || !strings.Contains(err.Error(), "no PTID column") {
+		t.Error("Expected:", "no PTID column", "got:", err)
+	}
+	if err := ioutil.WriteFile(path, []byte("PTID,MRN,RESEARCH_ID\nABCD092780,111\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := readCrosswalk(path)
+	if err != nil || len(entries) != 1 || entries[0] != (crosswalkEntry{"ABCD092780", "111", ""}) {
+		t.Error("Expected:", "ABCD092780 111 without research id", "got:", entries, err)
+	}
+}
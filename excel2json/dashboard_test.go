@@ -0,0 +1,24 @@
+package excel2json
+
+import (
+	"testing"
+)
+
+// TestNewDashboardOne
+func TestNewDashboardOne(t *testing.T) {
+	t.Log("Test for newDashboard - the fix reasons found, rule included, in the order of the taxonomy")
+	reset()
+	allWorkbooks = []workbook{{Path: "fu.xlsx"}}
+	row := rowRef{Path: "fu.xlsx", Sheet: 1, Row: 2}
+	for _, reason := range []string{fixRule, fixMissingDate, fixRule} {
+		allFix = append(allFix, fix{Type: "fix", Reason: reason, Source: source{Rows: []rowRef{row}}})
+	}
+	d := newDashboard()
+	if len(d.Reasons) != 2 || d.Reasons[0] != fixMissingDate || d.Reasons[1] != fixRule {
+		t.Fatal("Expected:", []string{fixMissingDate, fixRule}, "got:", d.Reasons)
+	}
+	counts := d.FixReasons["fu.xlsx"]
+	if len(counts) != 2 || counts[1].Count != 2 {
+		t.Error("Expected:", "2 rule fix events", "got:", counts)
+	}
+}
@@ -0,0 +1,84 @@
+package excel2json
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeOutput writes the events to a JSON file one after another, as WriteToJSON does,
+// and returns its path.
+func writeOutput(t *testing.T, name string, events ...interface{}) string {
+	path := filepath.Join(t.TempDir(), name)
+	var buf bytes.Buffer
+	for _, ev := range events {
+		j, err := json.Marshal(ev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Write(j)
+	}
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestDiffOutputsOne
+func TestDiffOutputsOne(t *testing.T) {
+	t.Log("Test for diffOutputs - the same event, the same date and the only events left are paired")
+	src := func(row int) source {
+		return source{Type: "followup", Path: []string{"fu.xlsx"}, Rows: []rowRef{{Path: "fu.xlsx", Sheet: 1, Row: row}}}
+	}
+	oldPath := writeOutput(t, "old.json",
+		death{Type: "death", PTID: "ABCD092780", Date: "2010-01-01", Source: src(2)},
+		te{Type: "stroke", PTID: "ABCD092780", Date: "2005-01-01", When: 1},
+		te{Type: "tia", PTID: "ABCD092780", Date: "2006-01-01", Source: src(2)},
+		general{Type: "arh", PTID: "EFGH010180", Date: "2007-01-01", Code: 1},
+		general{Type: "arh", PTID: "EFGH010180", Date: "2003-01-01", Code: 2},
+		general{Type: "arh", PTID: "EFGH010180", Date: "2004-01-01", Code: 2})
+	newPath := writeOutput(t, "new.json",
+		death{Type: "death", PTID: "ABCD092780", Date: "2010-02-01", Source: src(2)},
+		te{Type: "stroke", PTID: "ABCD092780", Date: "2005-01-01", When: 2},
+		// only the source is different
+		te{Type: "tia", PTID: "ABCD092780", Date: "2006-01-01", Source: src(3)},
+		general{Type: "arh", PTID: "EFGH010180", Date: "2008-01-01", Code: 1},
+		general{Type: "arh", PTID: "EFGH010180", Date: "2007-01-01", Code: 1})
+	diffs := diffOutputs(readOutput(e, oldPath), readOutput(e, newPath))
+	if len(diffs) != 2 || diffs[0].PTID != "ABCD092780" || diffs[1].PTID != "EFGH010180" {
+		t.Fatal("Expected:", "the differences of 2 patients", "got:", diffs)
+	}
+
+	// the death left alone with a new date, and the stroke of the same date
+	a := diffs[0]
+	if len(a.Added) != 0 || len(a.Removed) != 0 || len(a.Changed) != 2 {
+		t.Fatal("Expected:", "2 changed events", "got:", a)
+	}
+	for _, c := range a.Changed {
+		want := map[string]string{"death": "date", "stroke": "when"}[c.Type]
+		if len(c.Fields) != 1 || c.Fields[0].Field != want {
+			t.Error("Expected:", "the", want, "of the", c.Type, "changed", "got:", c.Fields)
+		}
+	}
+	if c := a.Changed[0]; c.OldDate != "2010-01-01" || c.NewDate != "2010-02-01" {
+		t.Error("Expected:", "2010-01-01 -> 2010-02-01", "got:", c.OldDate, c.NewDate)
+	}
+
+	// two arh events left in the old output and one in the new output are not paired
+	b := diffs[1]
+	if len(b.Added) != 1 || len(b.Removed) != 2 || len(b.Changed) != 0 {
+		t.Fatal("Expected:", "1 added and 2 removed events", "got:", b)
+	}
+	if d := describe(b.Added[0]); d != "arh 2008-01-01" {
+		t.Error("Expected:", "arh 2008-01-01", "got:", d)
+	}
+
+	var out bytes.Buffer
+	writeDiffText(&out, diffs)
+	if !strings.Contains(out.String(), "patients: 2 added: 1 removed: 2 changed: 2") {
+		t.Error("Expected:", "patients: 2 added: 1 removed: 2 changed: 2", "got:", out.String())
+	}
+}
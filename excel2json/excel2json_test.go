@@ -0,0 +1,67 @@
+package excel2json
+
+import (
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tealeg/xlsx"
+)
+
+// the error log of the tests, the messages are not checked
+var e = log.New(ioutil.Discard, "ERROR: ", 0)
+
+// the columns of the follow_up sheets of the tests
+var testColumns = []string{"PTID", "FU_D", "DIED", "DTH_D", "STATUS", "FU_STATUS", "X_STATUS", "STATUS=L DATE",
+	"DATEOR", "SURVIVAL", "FUMI", "FUMI_D"}
+
+// writeWorkbook writes an excel file with one follow_up sheet, the first row is the header row,
+// to the folder dir, and returns its path.
+func writeWorkbook(t *testing.T, dir string, name string, rows [][]string) string {
+	f := xlsx.NewFile()
+	sheet, err := f.AddSheet("FU")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rows {
+		row := sheet.AddRow()
+		for _, v := range r {
+			row.AddCell().SetString(v)
+		}
+	}
+	path := filepath.Join(dir, name)
+	if err := f.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// testOptions returns the options of a conversion of the folder dir with the test columns.
+func testOptions(t *testing.T, dir string) Options {
+	columns := filepath.Join(dir, "columns.txt")
+	if err := ioutil.WriteFile(columns, []byte(strings.Join(testColumns, "\n")), 0666); err != nil {
+		t.Fatal(err)
+	}
+	return Options{Columns: columns, Root: dir}
+}
+
+// convertFolder converts the excel files of the folder dir with the options o,
+// the events are left in the slices.
+func convertFolder(t *testing.T, dir string, o Options) {
+	reset()
+	jsonFile, err := ioutil.TempFile(t.TempDir(), "events*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer jsonFile.Close()
+	LoopAllFiles(e, dir, jsonFile, o)
+}
+
+// reset empties the slices of the events and the workbooks of an earlier test,
+// and the status precedence it loaded.
+func reset() {
+	takeEvents()
+	allWorkbooks, precedence = nil, nil
+}
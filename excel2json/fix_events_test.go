@@ -0,0 +1,36 @@
+package excel2json
+
+import (
+	"testing"
+)
+
+// TestFixReasonsOne
+func TestFixReasonsOne(t *testing.T) {
+	t.Log("Test for the fix events of invalid codes and conflicting sources")
+	dir := t.TempDir()
+	writeWorkbook(t, dir, "fu.xlsx", [][]string{
+		{"PTID", "FU_D", "DIED", "DTH_D", "STATUS", "FU_STATUS", "TE1", "TE1_D"},
+		{"ABCD092780", "2010-05-01", "1", "2010-06-01", "D", "D", "5", "2009-01-01"},
+		{"ABCD092780", "2010-07-01", "1", "2010-08-01", "D", "A", "", ""},
+	})
+	convertFolder(t, dir, testOptions(t, dir))
+	reasons := map[string][]string{}
+	for _, f := range allFix {
+		reasons[f.Reason] = append(reasons[f.Reason], f.Column)
+	}
+	if c := reasons[fixInvalidCode]; len(c) != 1 || c[0] != "TE1" {
+		t.Error("Expected:", "an invalid_code fix event of TE1", "got:", c)
+	}
+	// the STATUS columns of the second row, and the death date of the first row that is dropped
+	if c := reasons[fixConflictingSources]; len(c) != 2 {
+		t.Error("Expected:", "2 conflicting_sources fix events", "got:", c)
+	}
+	if len(allDths) != 1 || allDths[0].Date != "2010-08-01" {
+		t.Error("Expected:", "the death of 2010-08-01", "got:", allDths)
+	}
+	for _, c := range scorecards() {
+		if c.InvalidCodes != 1 {
+			t.Error("Expected:", 1, "invalid code, got:", c.InvalidCodes)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package excel2json
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestInspectWorkbookOne
+func TestInspectWorkbookOne(t *testing.T) {
+	t.Log("Test for InspectWorkbook - the STATUS columns are reconciled as in a conversion")
+	dir := t.TempDir()
+	path := writeWorkbook(t, dir, "fu.xlsx", [][]string{
+		{"PTID", "FU_D", "DIED", "DTH_D", "STATUS", "FU_STATUS", "STATUS=L DATE"},
+		{"ABCD092780", "2010-05-01", "0", "", "A", "L", "2011-01-01"},
+	})
+	o := testOptions(t, dir)
+
+	convertFolder(t, dir, o)
+	if len(allLostFollowups) != 1 {
+		t.Fatal("Expected:", 1, "lost_to_followup event in the conversion, got:", len(allLostFollowups))
+	}
+
+	reset()
+	var out bytes.Buffer
+	InspectWorkbook(e, path, o, &out)
+	if !strings.Contains(out.String(), "lost_to_followup 2011-01-01") {
+		t.Error("Expected:", "lost_to_followup 2011-01-01", "got:", out.String())
+	}
+}
+
+// TestInspectWorkbookTwo
+func TestInspectWorkbookTwo(t *testing.T) {
+	t.Log("Test for InspectWorkbook - the rows are read with the index operations and the rules of the options")
+	dir := t.TempDir()
+	path := writeWorkbook(t, dir, "fu.xlsx", [][]string{
+		{"PTID", "FU_D", "DIED", "DTH_D", "DATEOR"},
+		{"ABCD092780", "2010-05-01", "1", "", "2005-01-01"},
+	})
+	o := testOptions(t, dir)
+	o.IndexOps = true
+	o.Rules = filepath.Join(dir, "rules.json")
+	rule := `[{"name": "death date", "expr": "DIED == 1 && empty(DTH_D)", "message": "DIED is 1 without DTH_D"}]`
+	if err := ioutil.WriteFile(o.Rules, []byte(rule), 0666); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { opts = Options{}; loadTables(e) }()
+
+	reset()
+	var out bytes.Buffer
+	InspectWorkbook(e, path, o, &out)
+	for _, want := range []string{"operation 2005-01-01", "fix row: " + fixRule} {
+		if !strings.Contains(out.String(), want) {
+			t.Error("Expected:", want, "got:", out.String())
+		}
+	}
+}
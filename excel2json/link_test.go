@@ -0,0 +1,33 @@
+package excel2json
+
+import (
+	"testing"
+)
+
+// TestLinkOperationsOne
+func TestLinkOperationsOne(t *testing.T) {
+	t.Log("Test for linkOperations - the ids are numbered per patient and do not change when other patients are added")
+	reset()
+	allOperation = []operation{
+		{PTID: "EFGH010180", Date: "2012-03-01"},
+		{PTID: "EFGH010180", Date: "2005-01-01"},
+	}
+	linkOperations()
+	if allOperation[0].ID != "EFGH010180-1" || allOperation[1].ID != "EFGH010180-2" {
+		t.Fatal("Expected:", "EFGH010180-1", "EFGH010180-2", "got:", allOperation[0].ID, allOperation[1].ID)
+	}
+	if p := allOperation[1].Parent; p == nil || *p != "EFGH010180-1" {
+		t.Error("Expected:", "parent EFGH010180-1", "got:", p)
+	}
+	if c := allOperation[0].Children; len(c) != 1 || c[0] != "EFGH010180-2" {
+		t.Error("Expected:", "children [EFGH010180-2]", "got:", c)
+	}
+
+	allOperation = append(allOperation, operation{PTID: "ABCD092780", Date: "2001-01-01"})
+	linkOperations()
+	for _, o := range allOperation {
+		if o.PTID == "EFGH010180" && o.Date == "2012-03-01" && o.ID != "EFGH010180-2" {
+			t.Error("Expected:", "EFGH010180-2", "got:", o.ID)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package excel2json
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSetOperativeOne
+func TestSetOperativeOne(t *testing.T) {
+	t.Log("Test for setOperative - the 30 days and the discharge date of the nearest operation, and the coded operative")
+	discharge := "2005-03-01"
+	one, zero := 1, 0
+	cases := []struct {
+		date  string
+		coded *int
+		want  int
+		op    string
+		msg   string
+	}{
+		// up to 30 days after the operation
+		{"2008-01-31", nil, 1, "ABCD092780-2", ""},
+		{"2008-02-01", nil, 0, "ABCD092780-2", ""},
+		// after 30 days but on or before the discharge date
+		{"2005-03-01", nil, 1, "ABCD092780-1", ""},
+		{"2005-03-02", nil, 0, "ABCD092780-1", ""},
+		// the coded operative disagrees
+		{"2008-01-15", &zero, 1, "ABCD092780-2", "SURVIVAL codes the death as not operative, but it is operative: 14 days"},
+		{"2005-03-02", &one, 0, "ABCD092780-1", "SURVIVAL codes the death as operative, but it is not operative: 60 days after the operation of '2005-01-01', discharged on '2005-03-01'"},
+		{"2008-02-01", &zero, 0, "ABCD092780-2", ""},
+		// no operation on or before the death
+		{"2004-12-31", &one, 0, "", "there is no operation on or before the death"},
+	}
+	for _, c := range cases {
+		reset()
+		allOperation = []operation{
+			{ID: "ABCD092780-1", PTID: "ABCD092780", Date: "2005-01-01", Discharge: &discharge},
+			{ID: "ABCD092780-2", PTID: "ABCD092780", Date: "2008-01-01"},
+		}
+		allDths = []death{{PTID: "ABCD092780", Type: "death", Date: c.date, CodedOp: c.coded}}
+		setOperative()
+		d := allDths[0]
+		if d.Operative != c.want {
+			t.Error("Expected:", c.want, "got:", d.Operative, "for", c.date)
+		}
+		if c.msg == "" && len(d.Fix) != 0 || c.msg != "" && (len(d.Fix) != 1 || !strings.Contains(d.Fix[0].Msg, c.msg)) {
+			t.Error("Expected:", c.msg, "got:", d.Fix, "for", c.date)
+		}
+		if c.op == "" && d.Evidence != nil || c.op != "" && (d.Evidence == nil || d.Evidence.OperationID != c.op) {
+			t.Error("Expected:", "the evidence of", c.op, "got:", d.Evidence, "for", c.date)
+		}
+	}
+}
+
+// TestSetOperativeTwo
+func TestSetOperativeTwo(t *testing.T) {
+	t.Log("Test for setOperative - without -index-operations the death is timed from the DATEOR of the row")
+	dir := t.TempDir()
+	writeWorkbook(t, dir, "fu.xlsx", [][]string{
+		{"PTID", "FU_D", "DIED", "DTH_D", "STATUS", "DATEOR", "SURVIVAL"},
+		{"ABCD092780", "2005-01-15", "1", "2005-01-15", "D", "2005-01-01", "0"},
+	})
+	convertFolder(t, dir, testOptions(t, dir))
+	if len(allDths) != 1 {
+		t.Fatal("Expected:", 1, "death, got:", len(allDths))
+	}
+	d := allDths[0]
+	if d.Operative != 1 || len(d.Fix) != 0 {
+		t.Error("Expected:", "an operative death without fix messages", "got:", d.Operative, d.Fix)
+	}
+	if d.Evidence == nil || d.Evidence.OperationDate != "2005-01-01" || d.Evidence.DaysAfter != 14 {
+		t.Error("Expected:", "the evidence of the DATEOR 2005-01-01", "got:", d.Evidence)
+	}
+}
@@ -0,0 +1,39 @@
+package excel2json
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestReadOperativeSheetOne
+func TestReadOperativeSheetOne(t *testing.T) {
+	t.Log("Test for readOperativeSheet - the surgeries are parsed with the procedure vocabulary")
+	reset()
+	opts.Procedures = filepath.Join(t.TempDir(), "procedures.json")
+	defer func() { opts.Procedures = ""; loadProcedures(e) }()
+	vocabulary := `[{"name": "AVR", "patterns": ["AVR", "aortic valve replacement"]}, {"name": "CABG"}]`
+	if err := ioutil.WriteFile(opts.Procedures, []byte(vocabulary), 0666); err != nil {
+		t.Fatal(err)
+	}
+	loadProcedures(e)
+
+	keys := []string{"PTID", "DATEOR", "SURGEON", "SURGERY"}
+	rows := []map[string]string{
+		{"PTID": "ABCD092780", "DATEOR": "2005-01-01", "SURGEON": "SMITH", "SURGERY": "Aortic valve replacement; CABG x2"},
+		{"PTID": "EFGH010180", "DATEOR": "2006-01-01", "SURGEON": "", "SURGERY": "ablation"},
+		{"PTID": "", "DATEOR": "", "SURGEON": "", "SURGERY": ""},
+	}
+	readOperativeSheet(e, "op.xlsx", "OPS", 0, rows, keys)
+	if len(allOperation) != 2 {
+		t.Fatal("Expected:", 2, "operations, got:", len(allOperation))
+	}
+	if got := strings.Join(allOperation[0].Surgeries, ","); got != "AVR,CABG" {
+		t.Error("Expected:", "AVR,CABG", "got:", got)
+	}
+	// a surgery that is not in the vocabulary is not an empty row
+	if o := allOperation[1]; o.Surgeries != nil || len(o.Fix) != 1 || o.Fix[0].Field != "surgeries" {
+		t.Error("Expected:", "a surgeries fix message", "got:", o.Surgeries, o.Fix)
+	}
+}
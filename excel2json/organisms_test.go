@@ -0,0 +1,92 @@
+package excel2json
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// TestNormalizeOrganismOne
+func TestNormalizeOrganismOne(t *testing.T) {
+	t.Log("Test for normalizeOrganism - the default organism table, ignoring case, punctuation and spacing")
+	opts = Options{}
+	loadOrganisms(e)
+	cases := []struct {
+		raw, name, group string
+	}{
+		{"S. aureus", "Staphylococcus aureus", "staphylococcal"},
+		{"  staph   AUREUS ", "Staphylococcus aureus", "staphylococcal"},
+		{"MRSA", "Staphylococcus aureus", "staphylococcal"},
+		{"Strep. bovis", "Streptococcus gallolyticus", "streptococcal"},
+		{"no growth", "Culture-negative", "culture-negative"},
+		{"Candida species", "Candida species", "fungal"},
+		{"Bartonella", "", ""},
+	}
+	for _, c := range cases {
+		raw := c.raw
+		s := general{Type: "sbe", Organism: &raw}
+		s.normalizeOrganism()
+		if c.name == "" {
+			if s.Species != nil || len(s.Fix) != 1 || s.Fix[0].Msg != "organism not in the organism table: 'Bartonella'" {
+				t.Error("Expected:", "a fix message", "got:", s.Species, s.Fix)
+			}
+		} else if s.Species == nil || *s.Species != c.name || *s.Group != c.group || len(s.Fix) != 0 {
+			t.Error("Expected:", c.name, c.group, "got:", s.Species, s.Group, s.Fix, "for", c.raw)
+		}
+		if *s.Organism != c.raw {
+			t.Error("Expected:", "the raw organism", c.raw, "got:", *s.Organism)
+		}
+	}
+	s := general{Type: "sbe"}
+	s.normalizeOrganism()
+	if s.Species != nil || len(s.Fix) != 0 {
+		t.Error("Expected:", "no organism", "got:", s.Species, s.Fix)
+	}
+}
+
+// TestNormalizeOrganismTwo
+func TestNormalizeOrganismTwo(t *testing.T) {
+	t.Log("Test for loadOrganisms - the synonyms of an organisms file replace the default table")
+	opts = Options{Organisms: filepath.Join(t.TempDir(), "organisms.json")}
+	defer func() { opts = Options{}; loadOrganisms(e) }()
+	table := `[{"name": "Cutibacterium acnes", "group": "other", "synonyms": ["P. acnes", "Propionibacterium acnes"]}]`
+	if err := ioutil.WriteFile(opts.Organisms, []byte(table), 0666); err != nil {
+		t.Fatal(err)
+	}
+	loadOrganisms(e)
+	for _, raw := range []string{"P acnes", "propionibacterium-acnes", "Cutibacterium acnes", "S aureus"} {
+		v := raw
+		s := general{Type: "sbe", Organism: &v}
+		s.normalizeOrganism()
+		if raw == "S aureus" {
+			if s.Species != nil {
+				t.Error("Expected:", "S aureus not in the table", "got:", *s.Species)
+			}
+		} else if s.Species == nil || *s.Species != "Cutibacterium acnes" || *s.Group != "other" {
+			t.Error("Expected:", "Cutibacterium acnes", "got:", s.Species, "for", raw)
+		}
+	}
+}
+
+// TestNormalizeOrganismThree
+func TestNormalizeOrganismThree(t *testing.T) {
+	t.Log("Test for normalizeOrganism - two names of the same organism are one sbe event, an unknown one is an invalid code")
+	dir := t.TempDir()
+	writeWorkbook(t, dir, "fu.xlsx", [][]string{
+		{"PTID", "FU_D", "DIED", "DTH_D", "STATUS", "SBE1", "SBE1_D", "SBE1 ORGANISM", "SBE2", "SBE2_D", "SBE2 ORGANISM"},
+		{"ABCD092780", "2010-05-01", "0", "", "A", "1", "2008-01-01", "S. aureus", "1", "2009-01-01", "Bartonella"},
+		{"ABCD092780", "2010-06-01", "0", "", "A", "1", "2008-01-01", "Staph aureus", "", "", ""},
+	})
+	convertFolder(t, dir, testOptions(t, dir))
+	if len(allSBE) != 2 {
+		t.Fatal("Expected:", 2, "sbe events, got:", len(allSBE))
+	}
+	if rows := allSBE[0].Source.Rows; len(rows) != 2 {
+		t.Error("Expected:", "the sbe of the two rows merged", "got:", rows)
+	}
+	for _, c := range scorecards() {
+		if c.InvalidCodes != 1 {
+			t.Error("Expected:", 1, "invalid code, got:", c.InvalidCodes)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package excel2json
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestParseSurgeriesOne
+func TestParseSurgeriesOne(t *testing.T) {
+	t.Log("Test for parseSurgeries - the procedures of a vocabulary, matched as whole words ignoring case")
+	opts = Options{Procedures: filepath.Join(t.TempDir(), "procedures.json")}
+	defer func() { opts = Options{}; loadProcedures(e) }()
+	vocabulary := `[{"name": "AVR", "patterns": ["AVR", "aortic valve replacement"]}, {"name": "CABG"}, {"name": "MV repair", "patterns": ["MV ?repair", "MVr"]}]`
+	if err := ioutil.WriteFile(opts.Procedures, []byte(vocabulary), 0666); err != nil {
+		t.Fatal(err)
+	}
+	loadProcedures(e)
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"Redo AVR + CABG x2", "AVR,CABG"},
+		// in the order of the vocabulary, once each
+		{"cabg, then avr and CABG", "AVR,CABG"},
+		{"Aortic valve replacement (mechanical)", "AVR"},
+		{"MVrepair; MVr", "MV repair"},
+		// only whole words
+		{"TAVR", ""},
+		{"AVRs and CABGx2", ""},
+	}
+	for _, c := range cases {
+		list, msg := parseSurgeries(c.text)
+		if got := strings.Join(list, ","); got != c.want {
+			t.Error("Expected:", c.want, "got:", got, "for", c.text)
+		}
+		if c.want == "" && (msg == nil || msg.Msg != "no procedure of the vocabulary in: '"+c.text+"'") {
+			t.Error("Expected:", "a surgeries fix message", "got:", msg, "for", c.text)
+		} else if c.want != "" && msg != nil {
+			t.Error("Expected:", "no fix message", "got:", msg, "for", c.text)
+		}
+	}
+}
+
+// TestParseSurgeriesTwo
+func TestParseSurgeriesTwo(t *testing.T) {
+	t.Log("Test for parseSurgeries - without a vocabulary the text is split on ;")
+	opts = Options{}
+	loadProcedures(e)
+	list, msg := parseSurgeries(" AVR; CABG x2 ;; ")
+	if strings.Join(list, ",") != "AVR,CABG x2" || msg != nil {
+		t.Error("Expected:", "AVR,CABG x2", "got:", list, msg)
+	}
+}
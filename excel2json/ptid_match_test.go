@@ -0,0 +1,92 @@
+package excel2json
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// TestFindDuplicatesOne
+func TestFindDuplicatesOne(t *testing.T) {
+	t.Log("Test for findDuplicates - PTIDs a few edits apart are linked by the birth date or a shared operation")
+	dir := t.TempDir()
+	writeWorkbook(t, dir, "fu.xlsx", [][]string{
+		{"PTID", "FU_D", "DIED", "DTH_D", "STATUS", "DATEOR"},
+		{"ABCD092780", "2010-05-01", "0", "", "A", "2005-01-01"},
+		{"ABCD092780", "2011-05-01", "0", "", "A", "2005-01-01"},
+		// letters swapped, the same birth date
+		{"ABDC092780", "2010-06-01", "0", "", "A", "2005-02-01"},
+		// one edit apart, but another birth year and operation
+		{"ABCD092781", "2010-07-01", "0", "", "A", "2006-01-01"},
+		// another birth date, the same operation
+		{"WXYZ010101", "2010-08-01", "0", "", "A", "2007-01-01"},
+		{"WXYZ010102", "2010-09-01", "0", "", "A", "2007-01-01"},
+	})
+	convertFolder(t, dir, testOptions(t, dir))
+	want := map[string]duplicate{
+		"ABCD092780": {Cluster: 1, Suggested: "ABCD092780", SameBirth: true},
+		"ABDC092780": {Cluster: 1, Suggested: "ABCD092780", Distance: 1, SameBirth: true},
+		"WXYZ010101": {Cluster: 2, Suggested: "WXYZ010101", SameBirth: true},
+		"WXYZ010102": {Cluster: 2, Suggested: "WXYZ010101", Distance: 1},
+	}
+	list := findDuplicates()
+	if len(list) != len(want) {
+		t.Fatal("Expected:", len(want), "likely duplicates, got:", list)
+	}
+	for _, d := range list {
+		w, ok := want[d.PTID]
+		if !ok || d.Cluster != w.Cluster || d.Suggested != w.Suggested || d.Distance != w.Distance || d.SameBirth != w.SameBirth {
+			t.Error("Expected:", w, "got:", d)
+		}
+	}
+	if d := list[3]; len(d.SharedOps) != 1 || d.SharedOps[0] != "2007-01-01" {
+		t.Error("Expected:", "the shared operation 2007-01-01", "got:", d.SharedOps)
+	}
+}
+
+// TestResolveAliasOne
+func TestResolveAliasOne(t *testing.T) {
+	t.Log("Test for resolveAlias - chains of aliases, and aliases that point back")
+	defer func() { aliases = nil }()
+	aliases = map[string]string{"A1": "B1", "B1": "C1", "X1": "X1", "L1": "L2", "L2": "L1"}
+	cases := []struct {
+		id, want string
+	}{
+		{"A1", "C1"},
+		{" B1 ", "C1"},
+		{"C1", "C1"},
+		{"X1", "X1"},
+		{"Z1", "Z1"},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if got := resolveAlias(c.id); got != c.want {
+			t.Error("Expected:", c.want, "got:", got, "for", c.id)
+		}
+	}
+	// a loop of aliases stops at one of them
+	if got := resolveAlias("L1"); got != "L1" && got != "L2" {
+		t.Error("Expected:", "L1 or L2", "got:", got)
+	}
+}
+
+// TestLoadAliasesOne
+func TestLoadAliasesOne(t *testing.T) {
+	t.Log("Test for loadAliases - the CSV and the JSON aliases files")
+	defer func() { opts, aliases = Options{}, nil }()
+	dir := t.TempDir()
+	files := map[string]string{
+		"aliases.csv":  "Note,ALIAS,PTID\nswapped, ABDC092780 ,ABCD092780\n",
+		"aliases.json": `{"ABDC092780": "ABCD092780"}`,
+	}
+	for name, content := range files {
+		opts.Aliases = filepath.Join(dir, name)
+		if err := ioutil.WriteFile(opts.Aliases, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		loadAliases(e)
+		if got := resolveAlias("ABDC092780"); got != "ABCD092780" {
+			t.Error("Expected:", "ABCD092780", "got:", got, "for", name)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package excel2json
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// evalRule parses the expression and returns its value for the row m.
+func evalRule(t *testing.T, expr string, m map[string]string) value {
+	p := &parser{src: expr}
+	n, err := p.parse()
+	if err != nil {
+		t.Fatal("Expected:", "no error", "got:", err, "for", expr)
+	}
+	return n.eval(&rowEnv{e: e, m: m})
+}
+
+// TestParserOne
+func TestParserOne(t *testing.T) {
+	t.Log("Test for parser - the precedence of the operators")
+	m := map[string]string{"DIED": "1", "DTH_D": "", "STATUS": "D", "SBE1 ORGANISM": "S. aureus"}
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{"DIED == 1 && empty(DTH_D)", true},
+		{"DIED == 0 && empty(DTH_D)", false},
+		// && binds tighter than ||
+		{"DIED == 0 && STATUS == 'A' || STATUS == 'D'", true},
+		{"DIED == 0 && (STATUS == 'A' || STATUS == 'D')", false},
+		{"STATUS == 'D' || DIED == 0 && STATUS == 'A'", true},
+		// ! binds tighter than && and ||
+		{"!empty(STATUS) && !empty(DTH_D)", false},
+		{"!(empty(STATUS) || empty(DTH_D))", false},
+		{"!!empty(DTH_D)", true},
+		// columns in brackets, strings in double quotes
+		{`[SBE1 ORGANISM] == "S. aureus"`, true},
+		{`matches(lower([SBE1 ORGANISM]), '^s\. ?aureus$')`, true},
+		{"STATUS < 'E' && STATUS >= 'D' && STATUS != 'd'", true},
+	}
+	for _, c := range cases {
+		if v := evalRule(t, c.expr, m); v.b != c.want {
+			t.Error("Expected:", c.want, "got:", v.b, "for", c.expr)
+		}
+	}
+}
+
+// TestParserTwo
+func TestParserTwo(t *testing.T) {
+	t.Log("Test for parser - invalid expressions")
+	cases := []struct {
+		expr string
+		err  string
+	}{
+		{"STATUS == 'D", "unterminated string"},
+		{"empty(DTH_D, DIED)", "wrong number of arguments of empty"},
+		{"days(DTH_D)", "wrong number of arguments of days"},
+		{"matches(STATUS, '(')", "missing closing )"},
+		{"matches(STATUS, STATUS)", "the pattern of matches is not a string"},
+		{"unknown(STATUS)", "unknown function unknown"},
+		{"DIED == 1 &&", "unexpected end"},
+		{"(DIED == 1", "missing )"},
+		{"DIED == (1 || 2)", "the operands of || are not true or false"},
+		{"empty(DIED) == 1", "the operands of == are not values"},
+		{"empty(empty(DIED))", "invalid argument 1 of empty"},
+		{"DIED == 1 DTH_D", "unexpected 'DTH_D'"},
+		{"[DTH_D == 1", "missing ]"},
+		{"DIED == 1.2.3", "invalid number '1.2.3'"},
+	}
+	for _, c := range cases {
+		p := &parser{src: c.expr}
+		if _, err := p.parse(); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Error("Expected:", c.err, "got:", err, "for", c.expr)
+		}
+	}
+}
+
+// TestParserThree
+func TestParserThree(t *testing.T) {
+	t.Log("Test for parser - the columns of an expression, in order and once")
+	p := &parser{src: "DIED == 1 && empty(DTH_D) || DIED == 2 && [SBE1 ORGANISM] == ''"}
+	if _, err := p.parse(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(p.columns, ",") != "DIED,DTH_D,SBE1 ORGANISM" {
+		t.Error("Expected:", "DIED,DTH_D,SBE1 ORGANISM", "got:", p.columns)
+	}
+}
+
+// TestCompareOne
+func TestCompareOne(t *testing.T) {
+	t.Log("Test for compare - as numbers if both values are numbers, else as strings")
+	cases := []struct {
+		op, a, b string
+		want     bool
+	}{
+		{"==", "1", "1.0", true},
+		{"!=", "1", "1.0", false},
+		{"<", "9", "10", true},
+		{"<", "9", "10a", false},
+		{">=", "-9", "0", false},
+		{"==", "", "0", false},
+		// a number and an empty value or a text are not comparable
+		{"<", "", "0", false},
+		{"!=", "", "1", true},
+		{">=", "", "1", false},
+		{">", "x", "1", false},
+		{"<", "2005-01-01", "2012-03-01", true},
+		{"<=", "D", "D", true},
+	}
+	for _, c := range cases {
+		if got := compare(c.op, c.a, c.b); got != c.want {
+			t.Error("Expected:", c.want, "got:", got, "for", c.a, c.op, c.b)
+		}
+	}
+}
+
+// TestRuleFunctionsOne
+func TestRuleFunctionsOne(t *testing.T) {
+	t.Log("Test for the functions of the expressions - empty, date and days")
+	m := map[string]string{"DATEOR": "2009-1-1", "FU_D": "01-31-09", "DTH_D": "  ", "BAD_D": "someday"}
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{"date(DATEOR)", "2009-01-01"},
+		{"date(FU_D)", "2009-01-31"},
+		{"date(BAD_D)", ""},
+		{"days(DATEOR, FU_D)", "30"},
+		{"days(FU_D, DATEOR)", "-30"},
+		{"days(DATEOR, BAD_D)", ""},
+		{"days(DATEOR, DTH_D)", ""},
+	}
+	for _, c := range cases {
+		if v := evalRule(t, c.expr, m); v.s != c.want {
+			t.Error("Expected:", c.want, "got:", v.s, "for", c.expr)
+		}
+	}
+	if !evalRule(t, "empty(DTH_D) && empty(NO_COLUMN) && !empty(FU_D)", m).b {
+		t.Error("Expected:", "DTH_D of spaces and a missing column to be empty")
+	}
+	if !evalRule(t, "days(DATEOR, FU_D) > 7", m).b {
+		t.Error("Expected:", "30 days to compare as a number")
+	}
+	// the days of an empty or invalid date are not less than 0
+	for _, expr := range []string{"days(DTH_D, FU_D) < 0", "days(FU_D, BAD_D) < 0", "!empty(FU_D) && days(NO_COLUMN, FU_D) < 0"} {
+		if evalRule(t, expr, m).b {
+			t.Error("Expected:", false, "got:", true, "for", expr)
+		}
+	}
+}
+
+// TestExpandOne
+func TestExpandOne(t *testing.T) {
+	t.Log("Test for expand - the columns in braces of a message")
+	r := &rowEnv{m: map[string]string{"DIED": "1", "SBE1 ORGANISM": " S. aureus "}}
+	cases := []struct {
+		msg, want string
+	}{
+		{"DIED is {DIED} without DTH_D", "DIED is 1 without DTH_D"},
+		{"organism '{SBE1 ORGANISM}'", "organism 'S. aureus'"},
+		{"{DTH_D} is empty", " is empty"},
+		{"no columns", "no columns"},
+	}
+	for _, c := range cases {
+		if got := r.expand(c.msg); got != c.want {
+			t.Error("Expected:", c.want, "got:", got)
+		}
+	}
+}
+
+// TestCheckRulesOne
+func TestCheckRulesOne(t *testing.T) {
+	t.Log("Test for checkRules - the rows without PTID are not checked")
+	dir := t.TempDir()
+	writeWorkbook(t, dir, "fu.xlsx", [][]string{
+		{"PTID", "FU_D", "DIED", "DTH_D", "STATUS"},
+		{"ABCD092780", "2010-05-01", "0", "", "A"},
+		{"", "", "0", "", ""},
+	})
+	o := testOptions(t, dir)
+	o.Rules = filepath.Join(t.TempDir(), "rules.json")
+	rules := `[{"name": "no death date", "expr": "DIED == 0 && empty(DTH_D)", "message": "DIED is {DIED}"}]`
+	if err := ioutil.WriteFile(o.Rules, []byte(rules), 0666); err != nil {
+		t.Fatal(err)
+	}
+	convertFolder(t, dir, o)
+	n := 0
+	for _, f := range allFix {
+		if f.Reason == fixRule {
+			n++
+			if f.PTID != "ABCD092780" {
+				t.Error("Expected:", "ABCD092780", "got:", f.PTID)
+			}
+		}
+	}
+	if n != 1 {
+		t.Error("Expected:", 1, "rule fix event, got:", n)
+	}
+}
@@ -0,0 +1,48 @@
+package excel2json
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestReadIncrementalOne
+func TestReadIncrementalOne(t *testing.T) {
+	t.Log("Test for readIncremental - the cached events of an unchanged workbook are reused, unless the fingerprint changed")
+	dir := t.TempDir()
+	writeWorkbook(t, dir, "fu.xlsx", [][]string{
+		{"PTID", "FU_D", "DIED", "DTH_D", "STATUS"},
+		{"ABCD092780", "2010-05-01", "0", "", "A"},
+	})
+	o := testOptions(t, dir)
+	o.StatePath = filepath.Join(t.TempDir(), "state.json")
+	convertFolder(t, dir, o)
+
+	// change the PTID of the cached events, so it shows whether they are reused
+	data, err := ioutil.ReadFile(o.StatePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), "ABCD092780", "WXYZ092780", -1))
+	if err := ioutil.WriteFile(o.StatePath, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		precedence string
+		want       string
+	}{
+		// the same hash and fingerprint, the cached events
+		{"", "WXYZ092780"},
+		// another status precedence, the workbook is read again
+		{"N,D,L,O,A,R", "ABCD092780"},
+	}
+	for _, c := range cases {
+		o.Precedence = c.precedence
+		convertFolder(t, dir, o)
+		if len(allFollowUps) != 1 || allFollowUps[0].PTID != c.want {
+			t.Error("Expected:", c.want, "got:", allFollowUps, "for the precedence", c.precedence)
+		}
+	}
+}
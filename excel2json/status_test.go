@@ -0,0 +1,63 @@
+package excel2json
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestReconcileStatusOne
+func TestReconcileStatusOne(t *testing.T) {
+	t.Log("Test for reconcileStatus - three or more STATUS columns, ties and the audit note")
+	defer func() { opts.Precedence, precedence = "", nil }()
+	columns := []string{"STATUS", "FU_STATUS", "X_STATUS", "Y_STATUS"}
+	cases := []struct {
+		precedence string
+		values     []string
+		want       string
+		conflict   bool
+		note       string
+	}{
+		{"", []string{"A", "", "A", ""}, "A", false, ""},
+		{"", []string{"", "", "", ""}, "", false, ""},
+		// empty columns take the status of the others
+		{"", []string{"", "", "L", ""}, "L", false, ""},
+		// the highest precedence of three different statuses
+		{"", []string{"A", "L", "D", "O"}, "D", true, "status 'A' of STATUS overridden by 'D' of X_STATUS"},
+		{"", []string{"D", "A", "L", ""}, "D", true, ""},
+		{"L,D,N,O,A,R", []string{"A", "D", "L", ""}, "L", true, "status 'A' of STATUS overridden by 'L' of X_STATUS, by the status precedence L,D,N,O,A,R"},
+		// codes that are left out rank below the others, and between the same rank the first column wins
+		{"D,L", []string{"A", "R", "", "O"}, "A", true, ""},
+		{"D,L", []string{"A", "R", "L", ""}, "L", true, "status 'A' of STATUS overridden by 'L' of X_STATUS"},
+		{"", []string{"X", "A", "Y", ""}, "A", true, "status 'X' of STATUS overridden by 'A' of FU_STATUS"},
+	}
+	for _, c := range cases {
+		opts.Precedence = c.precedence
+		loadPrecedence(e)
+		m := map[string]string{}
+		for i, v := range c.values {
+			m[columns[i]] = v
+		}
+		s := reconcileStatus(m, columns)
+		if s.Status != c.want || s.Conflict != c.conflict {
+			t.Error("Expected:", c.want, c.conflict, "got:", s.Status, s.Conflict, "for", c.values)
+		}
+		if c.note == "" && s.Note != "" || !strings.Contains(s.Note, c.note) {
+			t.Error("Expected:", c.note, "got:", s.Note, "for", c.values)
+		}
+	}
+}
+
+// TestReconcileStatusTwo
+func TestReconcileStatusTwo(t *testing.T) {
+	t.Log("Test for reconcileStatus - the fix messages of different and invalid statuses")
+	defer func() { precedence = nil }()
+	loadPrecedence(e)
+	s := reconcileStatus(map[string]string{"STATUS": "A", "FU_STATUS": "Z", "X_STATUS": "Z"}, []string{"STATUS", "FU_STATUS", "X_STATUS"})
+	if msg := s.conflictMsg().Msg; msg != "different Statuses: 'A', 'Z', 'Z'" {
+		t.Error("Expected:", "different Statuses: 'A', 'Z', 'Z'", "got:", msg)
+	}
+	// an invalid status is reported once
+	if msgs := s.invalidMsgs(); len(msgs) != 1 || msgs[0].Msg != "invalid value: 'Z'" {
+		t.Error("Expected:", "invalid value: 'Z'", "got:", msgs)
+	}
+}
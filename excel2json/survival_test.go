@@ -0,0 +1,58 @@
+package excel2json
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+// TestSurvivalRowsOne
+func TestSurvivalRowsOne(t *testing.T) {
+	t.Log("Test for survivalRows - the index operation, the end of follow-up and the first events")
+	reset()
+	lka := "2010-06-01"
+	allOperation = []operation{
+		{Type: "operation", PTID: "ABCD092780", Date: "2005-01-01"},
+		{Type: "operation", PTID: "ABCD092780", Date: "2008-01-01"},
+		{Type: "operation", PTID: "EFGH010180", Date: "2005-01-01"},
+		{Type: "operation", PTID: "IJKL020280", Date: "2005-01-01"},
+	}
+	allFollowUps = []followups{{Type: "followup", PTID: "ABCD092780", Date: "2010-01-01"}}
+	allLostFollowups = []lostFollowup{{Type: "lost_to_followup", PTID: "ABCD092780", Date: "2011-01-01", LkaDate: &lka}}
+	allStroke = []te{
+		{Type: "stroke", PTID: "ABCD092780", Date: "2004-01-01"},
+		{Type: "stroke", PTID: "ABCD092780", Date: "2006-01-01"},
+		{Type: "stroke", PTID: "EFGH010180", Date: "2007-01-01"},
+	}
+	allDths = []death{
+		{Type: "death", PTID: "EFGH010180", Date: "2006-01-01", PrmDth: 1},
+		{Type: "death", PTID: "IJKL020280", Date: "2004-12-01", Source: source{Path: []string{"fu.xlsx"}}},
+		{Type: "death", PTID: "MNOP030380", Date: "2009-01-01"},
+	}
+	var errlog bytes.Buffer
+	rows := survivalRows(log.New(&errlog, "ERROR: ", 0))
+	if len(rows) != 2 {
+		t.Fatal("Expected:", 2, "patients, got:", rows)
+	}
+
+	// censored at the last known alive date of the lost_to_followup event
+	a := rows[0]
+	if a.IndexDate != "2005-01-01" || a.EndDate != "2010-06-01" || a.Death != 0 || a.CensorReason != "lost_to_followup" ||
+		a.FollowupDays != 1977 {
+		t.Error("Expected:", "2005-01-01 to 2010-06-01, lost_to_followup", "got:", a)
+	}
+	// the stroke before the index operation does not count, the re-operation does
+	if s, r := a.Outcomes[0], a.Outcomes[2]; s != (outcomeTime{1, 365}) || r != (outcomeTime{1, 1095}) {
+		t.Error("Expected:", "a stroke after 365 days and a re-operation after 1095 days", "got:", s, r)
+	}
+
+	// the stroke after the death is censored at the death
+	b := rows[1]
+	if b.EndDate != "2006-01-01" || b.Death != 1 || b.Cause != 1 || b.CensorReason != "" || b.Outcomes[0] != (outcomeTime{0, 365}) {
+		t.Error("Expected:", "a death after 365 days without stroke", "got:", b)
+	}
+	if !strings.Contains(errlog.String(), "died before the first operation, left out of the survival dataset: IJKL020280") {
+		t.Error("Expected:", "IJKL020280 left out", "got:", errlog.String())
+	}
+}
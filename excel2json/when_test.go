@@ -0,0 +1,80 @@
+package excel2json
+
+import (
+	"testing"
+)
+
+// TestSetWhenOne
+func TestSetWhenOne(t *testing.T) {
+	t.Log("Test for setWhen - the early window in days or until the discharge date")
+	defer func() { opts.EarlyWindow = "" }()
+	discharge := "2005-01-10"
+	operations := []operation{
+		{PTID: "ABCD092780", Date: "2005-01-01", Discharge: &discharge},
+		{PTID: "ABCD092780", Date: "2008-01-01"},
+	}
+	cases := []struct {
+		window string
+		date   string
+		want   int
+		fix    bool
+	}{
+		// 30 days by default
+		{"", "2005-01-31", 1, false},
+		{"", "2005-02-01", 2, false},
+		{"30", "2005-01-01", 1, false},
+		{"7", "2005-01-08", 1, false},
+		{"7", "2005-01-09", 2, false},
+		// the nearest operation on or before the event
+		{"", "2008-01-15", 1, false},
+		{"", "2004-12-31", 0, true},
+		// until the discharge date of the operation
+		{"hospital", "2005-01-10", 1, false},
+		{"hospital", "2005-01-11", 2, false},
+		{"hospital", "2008-01-02", 0, true},
+	}
+	for _, c := range cases {
+		reset()
+		allOperation = operations
+		allStroke = []te{{PTID: "ABCD092780", Type: "stroke", Date: c.date}}
+		opts.EarlyWindow = c.window
+		if err := setWhen(); err != nil {
+			t.Fatal(err)
+		}
+		if s := allStroke[0]; s.When != c.want || (len(s.Fix) == 1) != c.fix {
+			t.Error("Expected:", c.want, c.fix, "got:", s.When, s.Fix, "for", c.window, c.date)
+		}
+	}
+}
+
+// TestSetWhenTwo
+func TestSetWhenTwo(t *testing.T) {
+	t.Log("Test for setWhen - an invalid early window")
+	defer func() { opts.EarlyWindow = "" }()
+	for _, window := range []string{"-1", "month", "hospitals"} {
+		opts.EarlyWindow = window
+		if err := setWhen(); err == nil {
+			t.Error("Expected:", "an error", "got:", err, "for", window)
+		}
+	}
+}
+
+// TestSetWhenThree
+func TestSetWhenThree(t *testing.T) {
+	t.Log("Test for setWhen - without -index-operations the events are timed from the DATEOR of the row")
+	dir := t.TempDir()
+	writeWorkbook(t, dir, "fu.xlsx", [][]string{
+		{"PTID", "FU_D", "DIED", "DTH_D", "STATUS", "DATEOR", "TE1", "TE1_D", "TE2", "TE2_D"},
+		{"ABCD092780", "2010-05-01", "0", "", "A", "2005-01-01", "2", "2005-01-10", "3", "2009-01-10"},
+	})
+	convertFolder(t, dir, testOptions(t, dir))
+	if len(allOperation) != 0 {
+		t.Error("Expected:", "no operation events", "got:", allOperation)
+	}
+	if len(allStroke) != 1 || allStroke[0].When != 1 || len(allStroke[0].Fix) != 0 {
+		t.Error("Expected:", "an early stroke", "got:", allStroke)
+	}
+	if len(allTIA) != 1 || allTIA[0].When != 2 || len(allTIA[0].Fix) != 0 {
+		t.Error("Expected:", "a late tia", "got:", allTIA)
+	}
+}
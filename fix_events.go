@@ -0,0 +1,85 @@
+package excel2json
+
+// reasons of fix events
+const (
+	fixMissingDate        = "missing_date"        // a code or notes exist but the date is empty
+	fixInvalidDate        = "invalid_date"        // the date cannot be parsed to YYYY-MM-DD
+	fixInvalidCode        = "invalid_code"        // the code that decides the event is invalid
+	fixConflictingSources = "conflicting_sources" // columns or records disagree with each other
+	fixRule               = "rule"                // the row breaks a rule of the rules file
+)
+
+// newFix creates a fix event of the patient id for a row that cannot become
+// an event of eventType, r is the row and m holds its values,
+// columns are the names of the columns that the event would use,
+// the first one is the column that needs fixing.
+func newFix(id string, eventType string, reason string, r rowRef, m map[string]string, columns ...string) fix {
+	f := fix{
+		PTID:      id,
+		Type:      "fix",
+		EventType: eventType,
+		Reason:    reason,
+		Column:    columns[0],
+		Source:    newSource(r, m, columns...)}
+	// the row reference with the cells used
+	f.Row = f.Source.Rows[0]
+	return f
+}
+
+// dateConflict returns the fix event of the death d that is dropped because
+// the same patient has the death kept with another date.
+func (d death) dateConflict(kept death) fix {
+	f := fix{
+		Type:       "fix",
+		MRN:        d.MRN,
+		ResearchID: d.ResearchID,
+		PTID:       d.PTID,
+		EventType:  "death",
+		Reason:     fixConflictingSources,
+		Column:     "DTH_D",
+		Msg:        "different death dates: '" + d.Date + "', '" + kept.Date + "', the later one is kept",
+		Source: source{
+			Type: d.Source.Type,
+			Path: append([]string{}, d.Source.Path...),
+			Rows: append([]rowRef{}, d.Source.Rows...)}}
+	if len(f.Source.Rows) > 0 {
+		f.Row = f.Source.Rows[0]
+	}
+	return f
+}
+
+// fixReason returns the reason of a fix event according to the indicator
+// returned by helper.CheckDateFormat.
+func fixReason(est int) string {
+	if est == 3 {
+		return fixInvalidDate
+	}
+	return fixMissingDate
+}
+
+// teEventType returns the type of event that a TE code creates.
+func teEventType(code string) string {
+	if code == "3" {
+		return "tia"
+	}
+	return "stroke"
+}
+
+// legacy returns the fix event in the old format: a general event
+// with a placeholder date, "1900-02-02" for lost_to_followup events
+// and "1900-01-01" for the others.
+func (f fix) legacy() general {
+	g := general{
+		Type:       f.Type,
+		MRN:        f.MRN,
+		ResearchID: f.ResearchID,
+		PTID:       f.PTID,
+		Date:       "1900-01-01",
+		DateEst:    1,
+		Msg:        f.Msg,
+		Source:     f.Source}
+	if f.EventType == "lost_to_followup" {
+		g.Date = "1900-02-02"
+	}
+	return g
+}
@@ -8,6 +8,8 @@ import (
 	"os/exec"
 	"strings"
 	"testing"
+
+	"github.com/tealeg/xlsx"
 )
 
 var (
@@ -128,36 +130,6 @@ func TestIntInSliceTwo(t *testing.T) {
 	}
 }
 
-// TestAssignStatusOne
-func TestAssignStatusOne(t *testing.T) {
-	t.Log("Test for AssignStatus - 2 different non empty values")
-	s1, s2 := "aa", "bb"
-	err := AssignStatus(e, path, row, sheet, &s1, &s2)
-	if err == nil {
-		t.Error("Something goes wrong here!")
-	}
-
-}
-
-func TestAssignStatusTwo(t *testing.T) {
-	t.Log("Test for AssignStatus - s1 is empty while s2 is not")
-	s1, s2 := "", "bb"
-	err := AssignStatus(e, path, row, sheet, &s1, &s2)
-	if s1 != "bb" || err != nil {
-		t.Error("Expected:", s2, "got:", s1)
-	}
-
-}
-
-func TestAssignStatusThree(t *testing.T) {
-	t.Log("Test for AssignStatus - s2 is empty while s1 is not")
-	s2, s1 := "", "bb"
-	err := AssignStatus(e, path, row, sheet, &s1, &s2)
-	if s1 != "bb" || err != nil {
-		t.Error("Expected:", s1, "got:", s1)
-	}
-}
-
 func TestAssignPTIDOne(t *testing.T) {
 	t.Log("Test for AssignStatus - 2 different non empty values")
 	s1, s2 := "aa", "bb"
@@ -258,55 +230,161 @@ func TestCheckPtidColumnsFour(t *testing.T) {
 	t.Fatalf("process ran with err %v, want exit status 1", err)
 }
 
-// TestCheckStatusColumnsOne
-func TestCheckStatusColumnsOne(t *testing.T) {
-	t.Log("Test for no Status column")
-	keys = []string{"Name", "AddressPT"}
-	if os.Getenv("BE_CRASHER") == "1" {
-		CheckStatusColumns(e, path, sheet, keys)
-		return
+// TestSubPathOne
+func TestSubPathOne(t *testing.T) {
+	t.Log("Test for SubPath - file inside the root folder")
+	sub, err := SubPath("registry/aortic/2016/fu.xlsx", "registry/aortic")
+	if sub != "2016/fu.xlsx" || err != nil {
+		t.Error("Expected:", "2016/fu.xlsx", "got:", sub, err)
 	}
-	cmd := exec.Command(os.Args[0], "-test.run=TestCheckStatusColumnsOne")
-	cmd.Env = append(os.Environ(), "BE_CRASHER=1")
-	err := cmd.Run()
-	if e, ok := err.(*exec.ExitError); ok && !e.Success() {
-		return
+}
+
+// TestSubPathTwo
+func TestSubPathTwo(t *testing.T) {
+	t.Log("Test for SubPath - file outside the root folder")
+	_, err := SubPath("registry/mitral/fu.xlsx", "registry/aortic")
+	if err == nil {
+		t.Error("Something goes wrong: the file is outside the root folder!")
 	}
-	t.Fatalf("process ran with err %v, want exit status 1", err)
+}
 
+// TestStatusColumnsOne
+func TestStatusColumnsOne(t *testing.T) {
+	t.Log("Test for StatusColumns - the columns that end with STATUS")
+	header := []string{"Name", "STATUS_1", "follow_STATUS", "STATUS"}
+	status := StatusColumns(header)
+	if len(status) != 2 || status[0] != "follow_STATUS" || status[1] != "STATUS" {
+		t.Error("Expected:", []string{"follow_STATUS", "STATUS"}, "got:", status)
+	}
 }
 
-func TestCheckStatusColumnsTwo(t *testing.T) {
-	t.Log("Test for only one Status column")
-	keys = []string{"FU_STATUS", "Name", "AddressPT", "PTID111"}
-	s1, s2 := CheckStatusColumns(e, path, sheet, keys)
-	if s1 != "FU_STATUS" || s1 != s2 {
-		t.Errorf("Expected FU_STATUS, got %s", s1)
+// TestUnexpectedColumnsOne
+func TestUnexpectedColumnsOne(t *testing.T) {
+	t.Log("Test for UnexpectedColumns - columns that match none of the patterns")
+	header := []string{"PTID", "FU_D", "FUREOP_D1", "COMMENTS"}
+	unexpected := UnexpectedColumns(header, []string{"PTID", "FU_D", "FUREOP_D"})
+	if len(unexpected) != 1 || unexpected[0] != "COMMENTS" {
+		t.Error("Expected:", []string{"COMMENTS"}, "got:", unexpected)
 	}
 }
 
-func TestCheckStatusColumnsThree(t *testing.T) {
-	t.Log("Test for two Status columns")
-	keys = []string{"Name", "STATUS_1", "follow_STATUS", "STATUS"}
-	s1, s2 := CheckStatusColumns(e, path, sheet, keys)
-	if s1 != "follow_STATUS" || s2 != "STATUS" {
-		t.Errorf("Expected follow_STATUS, got %s. Expected STATUS, got %s", s1, s2)
+// TestOperationDateColumnOne
+func TestOperationDateColumnOne(t *testing.T) {
+	t.Log("Test for OperationDateColumn - the column of the date of surgery")
+	header := []string{"PTID", "FU_D", "INDEX_DATE_OR", "COMMENTS"}
+	k := OperationDateColumn(header)
+	if k != "INDEX_DATE_OR" {
+		t.Error("Expected:", "INDEX_DATE_OR", "got:", k)
+	}
+	if k = OperationDateColumn(header[:2]); k != "" {
+		t.Error("Expected:", "", "got:", k)
 	}
 }
 
-// TestCheckStatusColumnsFour
-func TestCheckStatusColumnsFour(t *testing.T) {
-	t.Log("Test for more than 2 Status columns")
-	keys = []string{"Name", "AddressSTATUS", "PTID_STATUS", "Second_STATUS"}
-	if os.Getenv("BE_CRASHER") == "1" {
-		CheckStatusColumns(e, path, sheet, keys)
-		return
+// newSheet returns a sheet named name with a header row
+func newSheet(name string, header ...string) *xlsx.Sheet {
+	sheet, _ := xlsx.NewFile().AddSheet(name)
+	row := sheet.AddRow()
+	for _, h := range header {
+		row.AddCell().SetString(h)
 	}
-	cmd := exec.Command(os.Args[0], "-test.run=TestCheckStatusColumnsFour")
-	cmd.Env = append(os.Environ(), "BE_CRASHER=1")
-	err := cmd.Run()
-	if e, ok := err.(*exec.ExitError); ok && !e.Success() {
-		return
+	return sheet
+}
+
+// TestClassifySheetOne
+func TestClassifySheetOne(t *testing.T) {
+	t.Log("Test for ClassifySheet - the default rules")
+	sheetType, _ := ClassifySheet(newSheet("FU", "PTID", "FU_D", "DIED", "DTH_D"), DefaultSheetClasses)
+	if sheetType != "followup" {
+		t.Error("Expected:", "followup", "got:", sheetType)
+	}
+	sheetType, _ = ClassifySheet(newSheet("FU", "IGNORE", "FU_D", "DIED", "DTH_D"), DefaultSheetClasses)
+	if sheetType != "" {
+		t.Error("Expected:", "", "got:", sheetType)
+	}
+}
+
+// TestClassifySheetTwo
+func TestClassifySheetTwo(t *testing.T) {
+	t.Log("Test for ClassifySheet - sheet name patterns and optional headers")
+	classes := []SheetClass{
+		{Type: "followup", Required: []string{"PTID"}},
+		{Type: "echo", Required: []string{"PTID"}, Optional: []string{"LVEF"}},
+		{Type: "operative", Required: []string{"PTID"}, Optional: []string{"LVEF", "SURGEON"}, Names: []string{"(?i)^op"}},
+	}
+	sheetType, _ := ClassifySheet(newSheet("Echo 2016", "PTID", "LVEF", "SURGEON"), classes)
+	if sheetType != "echo" {
+		t.Error("Expected:", "echo", "got:", sheetType)
+	}
+	sheetType, _ = ClassifySheet(newSheet("OPS", "PTID", "LVEF", "SURGEON"), classes)
+	if sheetType != "operative" {
+		t.Error("Expected:", "operative", "got:", sheetType)
+	}
+}
+
+// TestBirthDateOne
+func TestBirthDateOne(t *testing.T) {
+	t.Log("Test for BirthDate - the century pivot")
+	d, ok := BirthDate("ABCD092780", 20)
+	if d != "1980-09-27" || !ok {
+		t.Error("Expected:", "1980-09-27", "got:", d)
+	}
+	d, ok = BirthDate("ABCD092705", 20)
+	if d != "2005-09-27" || !ok {
+		t.Error("Expected:", "2005-09-27", "got:", d)
+	}
+}
+
+// TestBirthDateTwo
+func TestBirthDateTwo(t *testing.T) {
+	t.Log("Test for BirthDate - invalid PTIDs")
+	for _, id := range []string{"ABCD023080", "ABCD927", "ABCD0092780", ""} {
+		if d, ok := BirthDate(id, 20); ok {
+			t.Error("Expected:", "no birth date", "got:", d)
+		}
+	}
+}
+
+// TestBirthDateThree
+func TestBirthDateThree(t *testing.T) {
+	t.Log("Test for BirthDate - the PTIDs that CheckPtidFormat accepts, without leading zeros")
+	cases := []struct {
+		id, date string
+	}{
+		{"ABCD92780", "1980-09-27"},
+		{"ABCD09580", "1980-09-05"},
+		{"ABCD13180", "1980-01-31"},
+		{"ABCD1580", "1980-01-05"},
+		// the month has one digit when it can be either
+		{"ABCD11280", "1980-01-12"},
+	}
+	for _, c := range cases {
+		if !CheckPtidFormat(c.id, "", e, path, sheet, row) {
+			t.Error("Expected:", "a valid PTID", "got:", c.id)
+		}
+		if d, ok := BirthDate(c.id, 20); d != c.date || !ok {
+			t.Error("Expected:", c.date, "got:", d, "for", c.id)
+		}
+	}
+}
+
+// TestEditDistanceOne
+func TestEditDistanceOne(t *testing.T) {
+	t.Log("Test for EditDistance - transpositions and mistyped digits")
+	cases := []struct {
+		a, b string
+		d    int
+	}{
+		{"ABCD092780", "ABCD092780", 0},
+		{"ABCD092780", "BACD092780", 1},
+		{"ABCD092780", "ABCD092788", 1},
+		{"ABCD092780", "ABCD09278", 1},
+		{"ABCD092780", "BACD092788", 2},
+		{"ABCD092780", "WXYZ010101", 9},
+	}
+	for _, c := range cases {
+		if d := EditDistance(c.a, c.b); d != c.d {
+			t.Error("Expected:", c.d, "got:", d, "for", c.a, c.b)
+		}
 	}
-	t.Fatalf("process ran with err %v, want exit status 1", err)
 }
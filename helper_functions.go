@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -16,6 +17,9 @@ import (
 	"github.com/tealeg/xlsx"
 )
 
+// ExitFailure is the exit code when the program stops on an error.
+const ExitFailure = 2
+
 // CheckDateFormat checks the date format and returns a date string with the format YYYY-MM-DD, and an int indicator:
 // indicator equals 0 means the original date is parsed to the format YYYY-MM-DD correctly;
 // indicator equals 1 means the original date is missing some parts and now been fixed;
@@ -146,23 +150,9 @@ func FloatInSlice(i float64, list []float64) bool {
 func CheckErr(e *log.Logger, err error) {
 	if err != nil {
 		e.Println(err)             // print to error log
-		log.Fatalln("ERROR:", err) // print to terminal and then terminate
-	}
-}
-
-// AssignStatus returns false and assigns the non-empty status value
-// to the empty one when a file has two columns of status and one of them is empty;
-// returns true if the two statuses have different values and none of them is empty.
-func AssignStatus(s1 *string, s2 *string) bool {
-	if *s1 == *s2 {
-		return false
-	} else if *s1 != "" && *s2 != "" {
-		return true
-	} else if *s1 == "" {
-		*s1 = *s2
-		return false
+		log.Println("ERROR:", err) // print to terminal
+		os.Exit(ExitFailure)       // and then terminate
 	}
-	return false
 }
 
 // AssignPTID returns false and assigns the non-empty PTID value
@@ -210,26 +200,135 @@ func CheckHeaderRow(e *log.Logger, excelFilePath string) (bool, *xlsx.File) {
 
 }
 
-// CheckFollowups checks if the excel sheet is an empty sheet,
-// a follow_up sheet or a sheet that should be ignored.
-// Return true and a header row if the sheet is a follow_up sheet;
-// else return false and nil.
-func CheckFollowups(e *log.Logger, path string, j int, sheet *xlsx.Sheet) (bool, []string) {
+// SheetClass is a rule that detects the type of an excel sheet.
+type SheetClass struct {
+	Type     string   `json:"type"`     // type of the sheet, such as "followup"
+	Required []string `json:"required"` // headers the sheet must have
+	Optional []string `json:"optional"` // headers the sheet may have, the class with the most of them wins
+	Names    []string `json:"names"`    // regular expressions of the sheet name, one of them must match if any is set
+	Skip     []string `json:"skip"`     // values of cell A1 that mark a sheet to skip
+	// column patterns of the fields of the events, by field name, such as "surgeon": ["SURGEON"];
+	// a pattern matches the columns that start with it, the defaults are used for the fields not set
+	Columns map[string][]string `json:"columns"`
+}
+
+// DefaultSheetClasses are the rules used when no sheets file is given:
+// a follow_up sheet has FU_D, DIED and DTH_D, and a sheet with A1 "IGNORE" is skipped.
+var DefaultSheetClasses = []SheetClass{
+	{Type: "followup", Required: []string{"FU_D", "DIED", "DTH_D"}, Skip: []string{"IGNORE"}},
+}
+
+// NoHeaderRow is the reason ClassifySheet gives for a sheet with an empty A1 cell.
+const NoHeaderRow = "cell A1 is empty, the sheet has no header row"
+
+// ReadSheetClasses reads the sheet classes from a JSON file,
+// and returns an error if a class has no type or a name pattern is invalid.
+func ReadSheetClasses(path string) ([]SheetClass, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	classes := []SheetClass{}
+	if err := json.NewDecoder(file).Decode(&classes); err != nil {
+		return nil, err
+	}
+	for _, c := range classes {
+		if c.Type == "" {
+			return nil, fmt.Errorf("%s: a sheet class has no type", path)
+		}
+		for _, n := range c.Names {
+			if _, err := regexp.Compile(n); err != nil {
+				return nil, fmt.Errorf("%s: sheet class %s: %v", path, c.Type, err)
+			}
+		}
+	}
+	return classes, nil
+}
+
+// CheckSheetType checks the type of the excel sheet with the sheet classes.
+// Return the type and the header row, or "" and nil if no class matches;
+// a sheet with an empty A1 cell is written to the errlog.
+func CheckSheetType(e *log.Logger, path string, j int, sheet *xlsx.Sheet, classes []SheetClass) (string, []string) {
+	sheetType, reason := ClassifySheet(sheet, classes)
+	// if A1 is empty, write to errlog
+	if reason == NoHeaderRow {
+		e.Println(path, "Sheet #:", j+1, "THIS SHEET DOES NOT HAVE HEADER ROW!")
+	}
+	if sheetType == "" {
+		return "", nil
+	}
+	return sheetType, HeaderRow(sheet)
+}
+
+// ClassifySheet returns the type of the excel sheet, or "" if no class matches,
+// and the reason of the decision.
+func ClassifySheet(sheet *xlsx.Sheet, classes []SheetClass) (string, string) {
 
 	// assign the string value of A1 cell to v
 	v, _ := sheet.Cell(0, 0).String()
 
-	// if v equals empty string, write to errlog and return false, nil;
-	// if v equals "IGNORE", it means that this sheet should be skipped
+	// if v equals empty string, the sheet has no header row;
+	// if v is a skip marker such as "IGNORE", it means that this sheet should be skipped
 	if v == "" {
-		e.Println(path, "Sheet #:", j+1, "THIS SHEET DOES NOT HAVE HEADER ROW!")
-		return false, nil
-		// ignore files if A1 is "IGNORE"
-	} else if v == "IGNORE" {
-		return false, nil
+		return "", NoHeaderRow
+	}
+	for _, c := range classes {
+		for _, marker := range c.Skip {
+			if v == marker {
+				return "", "cell A1 is \"" + v + "\""
+			}
+		}
 	}
-	// if cell A1 is neither empty nor "IGNORE",
 	// use the slice keys to collect header row
+	keys := HeaderRow(sheet)
+	// the class with the most optional headers wins
+	best, bestScore := -1, -1
+	reasons := []string{}
+	for i, c := range classes {
+		missing := []string{}
+		for _, k := range c.Required {
+			if !StringInSlice(0, k, keys) {
+				missing = append(missing, k)
+			}
+		}
+		if len(missing) > 0 {
+			reasons = append(reasons, c.Type+": the header row has no "+strings.Join(missing, ", "))
+			continue
+		}
+		named := len(c.Names) == 0
+		for _, n := range c.Names {
+			if matched, _ := regexp.MatchString(n, sheet.Name); matched {
+				named = true
+			}
+		}
+		if !named {
+			reasons = append(reasons, c.Type+": the sheet name matches none of "+strings.Join(c.Names, ", "))
+			continue
+		}
+		score := 0
+		for _, k := range c.Optional {
+			if StringInSlice(0, k, keys) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return "", strings.Join(reasons, "; ")
+	}
+	c := classes[best]
+	reason := c.Type + ": the header row has " + strings.Join(c.Required, ", ")
+	if len(c.Optional) > 0 {
+		reason += fmt.Sprint(" and ", bestScore, " of ", len(c.Optional), " optional headers")
+	}
+	return c.Type, reason
+}
+
+// HeaderRow returns the values of the first row of a sheet.
+func HeaderRow(sheet *xlsx.Sheet) []string {
 	keys := []string{}
 	for _, row := range sheet.Rows {
 		for _, cell := range row.Cells {
@@ -239,13 +338,7 @@ func CheckFollowups(e *log.Logger, path string, j int, sheet *xlsx.Sheet) (bool,
 		}
 		break
 	}
-	// Check if the sheet is a follow up sheet by checking if header row contains "FU_D", "DIED" and "DTH_D"
-	// if not, returns false and nil;
-	// else returns true and keys
-	if StringInSlice(0, "FU_D", keys) && StringInSlice(0, "DIED", keys) && StringInSlice(0, "DTH_D", keys) {
-		return true, keys
-	}
-	return false, nil
+	return keys
 }
 
 // Close is a function that closes a file
@@ -307,16 +400,6 @@ func CheckFloatValue(value1 *float64, value2 string, list []float64) bool {
 	return false
 }
 
-// CheckStringValue returns true if a value only contains characters.
-func CheckStringValue(value string) bool {
-
-	matched, _ := regexp.MatchString("^[A-Za-z]*$", value)
-	if matched {
-		return true
-	}
-	return false
-}
-
 // CheckPtidColumns checks the number of PTID columns,
 // and returns the column names of PTID, assuming each file would have at most two PTID columns.
 // Parameters including:
@@ -326,12 +409,7 @@ func CheckStringValue(value string) bool {
 // keys - a slice that contains the header row
 func CheckPtidColumns(e *log.Logger, path string, j int, keys []string) (string, string) {
 	// create a slice that holds the column names that contains PTID
-	id := []string{}
-	for _, k := range keys {
-		if strings.Contains(k, "PTID") {
-			id = append(id, k)
-		}
-	}
+	id := PtidColumns(keys)
 	// if len is 2, we have 2 columns of PTID
 	if len(id) == 2 {
 		id1, id2 := id[0], id[1]
@@ -342,50 +420,101 @@ func CheckPtidColumns(e *log.Logger, path string, j int, keys []string) (string,
 		return id1, id2
 	} else if len(id) == 0 {
 		e.Println(path, "Sheet #:", j+1, "INFO: This file does not have PTID columns!")
-		os.Exit(1) // exit if it has invaid columns of PTID
+		os.Exit(ExitFailure) // exit if it has invaid columns of PTID
 		return "", ""
 	}
 	// else would be invaid as we assume each file would have at most two PTID columns,
 	// then an error message gets written and the program stops.
 	e.Println(path, "Sheet #:", j+1, "INFO: This file has more than two columns of PTID!")
-	os.Exit(1) // exit if it has invaid columns of PTID
+	os.Exit(ExitFailure) // exit if it has invaid columns of PTID
 	return "", ""
 }
 
-// CheckStatusColumns checks the number of status columns, and returns the column
-// names of status, assuming each file would have at most two status columns.
-// Parameters including:
-// e - a logger that records the error messages;
-// path - the path of the excel file;
-// j - the index of the sheets in that excel file;
-// keys - a slice that contains the header row
-func CheckStatusColumns(e *log.Logger, path string, j int, keys []string) (string, string) {
-	// create a slice that holds the column names of status that matches a certain pattern
+// PtidColumns returns the column names that contain PTID.
+func PtidColumns(keys []string) []string {
+	id := []string{}
+	for _, k := range keys {
+		if strings.Contains(k, "PTID") {
+			id = append(id, k)
+		}
+	}
+	return id
+}
+
+// StatusColumns returns the column names that end with STATUS.
+func StatusColumns(keys []string) []string {
 	status := []string{}
 	for _, k := range keys {
-		matched, err := regexp.MatchString("^.*STATUS$", k) // check status's pattern
-		CheckErr(e, err)
-		if matched {
+		if strings.HasSuffix(k, "STATUS") {
 			status = append(status, k)
 		}
 	}
-	// if len is 2, we have 2 columns of STATUS
-	if len(status) == 2 {
-		s1, s2 := status[0], status[1]
-		return s1, s2
-		// if len is 1, we have only one column of STATUS
-	} else if len(status) == 1 {
-		s1, s2 := status[0], status[0]
-		return s1, s2
-	} else if len(status) == 0 {
-		return "", ""
+	return status
+}
+
+// ptidFormat is the valid PTID format LLLFMMDDYY, the month and the day may have no leading zero,
+// and a PTID that can be read both ways, e.g. ABCD11280, has a one-digit month
+var ptidFormat = regexp.MustCompile("^(.{4})(0?[1-9]|1[012])(0?[1-9]|[12][0-9]|3[01])([0-9][0-9])$")
+
+// BirthDate returns the birth date YYYY-MM-DD that the MMDDYY part of a PTID with the
+// format LLLFMMDDYY encodes, the same formats as CheckPtidFormat, and false if the PTID has
+// another format or the date does not exist. Two-digit years up to pivot are in the 2000s,
+// the others in the 1900s.
+func BirthDate(id string, pivot int) (string, bool) {
+	parts := ptidFormat.FindStringSubmatch(strings.TrimSpace(id))
+	if parts == nil {
+		return "", false
+	}
+	month, _ := strconv.Atoi(parts[2])
+	day, _ := strconv.Atoi(parts[3])
+	year, _ := strconv.Atoi(parts[4])
+	if year <= pivot {
+		year += 2000
+	} else {
+		year += 1900
+	}
+	t, err := time.Parse("2006-01-02", fmt.Sprintf("%d-%02d-%02d", year, month, day))
+	if err != nil {
+		return "", false
 	}
+	return t.Format("2006-01-02"), true
+}
 
-	// else would be invaid as we assume each file would have at most two STATUS columns,
-	// then an error message gets written and the program stops.
-	e.Println(path, "Sheet #:", j+1, "INFO: This file has invalid numbers of Status columns!")
-	os.Exit(1)
-	return "", ""
+// EditDistance returns the Damerau-Levenshtein distance of two strings (the optimal string
+// alignment distance): the number of insertions, deletions, substitutions and transpositions
+// of adjacent characters that change a into b.
+func EditDistance(a string, b string) int {
+	s, t := []rune(a), []rune(b)
+	// d[i][j] is the distance of the first i runes of s and the first j runes of t
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = d[i-1][j] + 1 // deletion
+			if v := d[i][j-1] + 1; v < d[i][j] {
+				d[i][j] = v // insertion
+			}
+			if v := d[i-1][j-1] + cost; v < d[i][j] {
+				d[i][j] = v // substitution
+			}
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				if v := d[i-2][j-2] + 1; v < d[i][j] {
+					d[i][j] = v // transposition
+				}
+			}
+		}
+	}
+	return d[len(s)][len(t)]
 }
 
 // CheckPtidFormat checks if the format of PTID is LLLFDDMMYY;
@@ -395,12 +524,11 @@ func CheckPtidFormat(id string, operDate string, e *log.Logger, path string, j i
 	// remove spaces
 	id = strings.TrimSpace(id)
 	// valid PTID format: LLLFMMDDYY
-	matched, err := regexp.MatchString("^(.{4})(0?[1-9]|1[012])(0?[1-9]|[12][0-9]|3[01])([0-9][0-9])$", id)
-	CheckErr(e, err)
+	matched := ptidFormat.MatchString(id)
 
 	if id == "" && operDate != "" {
 		e.Println("PTID is missing but date of surgery exists.")
-		os.Exit(1)
+		os.Exit(ExitFailure)
 	}
 	if matched || id == "" {
 		return true
@@ -410,17 +538,29 @@ func CheckPtidFormat(id string, operDate string, e *log.Logger, path string, j i
 }
 
 // CheckColumnNames checks if the columns are the expected ones;
-// if not, print to the errorlog
-func CheckColumnNames(file string, e *log.Logger, keys []string, path string, j int) {
+// if not, print to the errorlog. Returns the unexpected column names.
+func CheckColumnNames(file string, e *log.Logger, keys []string, path string, j int) []string {
 	// read from the columns file
 	columns, err := ReadLines(file)
 	CheckErr(e, err)
+	unexpected := UnexpectedColumns(keys, columns)
+	for _, k := range unexpected {
+		e.Println(path, "Sheet #:", j+1, "INFO: Unexpected Column:", k)
+	}
+	return unexpected
+}
+
+// UnexpectedColumns returns the column names of keys that do not match
+// any of the column patterns of the columns file.
+func UnexpectedColumns(keys []string, columns []string) []string {
+	unexpected := []string{}
 	// keys are from the header row of the excel file
 	for _, k := range keys {
 		if !StringInSlice(1, k, columns) {
-			e.Println(path, "Sheet #:", j+1, "INFO: Unexpected Column:", k)
+			unexpected = append(unexpected, k)
 		}
 	}
+	return unexpected
 }
 
 // ReadLines reads a whole file from path into memory,
@@ -452,12 +592,26 @@ func ReadUserInput() string {
 	return file
 }
 
-// SubPath returns a sub path string from the full length path string
-// by detect the index of keyword.
-func SubPath(path string, keyword string) string {
-	i := strings.Index(path, keyword)
-	sub := path[i+len(keyword):]
-	return sub
+// SubPath returns the path of a file relative to the root folder,
+// and an error if the file lies outside the root folder.
+func SubPath(path string, root string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	sub, err := filepath.Rel(absRoot, absPath)
+	if err != nil {
+		return "", err
+	}
+	// a path starting with ".." is outside the root folder
+	if sub == ".." || strings.HasPrefix(sub, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the root folder %s", path, root)
+	}
+	return filepath.ToSlash(sub), nil
 }
 
 // OperationNotes returns a full-text meaning operation notes according to the code book
@@ -485,28 +639,6 @@ func DateLaterThan(date1 string, date2 string) bool {
 	return d1.After(d2)
 }
 
-// CompareDates returns the result of comparing date1 and date2.
-// Return 1 if date1 is within 30 days of date2;
-// else return 2.
-func CompareDates(e *log.Logger, date1 string, date2 string) int {
-
-	// parse string to date
-	d1, err := time.Parse("2006-01-02", date1)
-	CheckErr(e, err)
-	d2, err := time.Parse("2006-01-02", date2)
-	CheckErr(e, err)
-
-	// diff equals d1 minus d2
-	diff := d1.Sub(d2)
-	// transfer to days
-	days := int(diff.Hours() / 24)
-
-	if days >= 0 && days <= 30 {
-		return 1
-	}
-	return 2
-}
-
 // FollowupNotes returns a full-text meaning followup notes according to the code book
 func FollowupNotes(S1 string, fuNotes string, notes string,
 	reason string, plat int, coag int, poNyha float64) string {
@@ -673,16 +805,21 @@ func ArhCode(code string) string {
 func CheckOperationDate(e *log.Logger, path string, j int, i int, keys []string, m map[string]string) (string, int) {
 	operDate, operEst := "", 2
 	// get the date of surgery
-	for _, k := range keys {
-		matched1, _ := regexp.MatchString("^.*DATEOR$", k)
-		matched2, _ := regexp.MatchString("^.*DATE_OR$", k)
-		if matched1 {
-			operDate, operEst = CheckDateFormat(e, path, j, i, "DATEOR", m[k])
-
-		} else if matched2 {
-			operDate, operEst = CheckDateFormat(e, path, j, i, "DATEOR", m[k])
-		}
+	if k := OperationDateColumn(keys); k != "" {
+		operDate, operEst = CheckDateFormat(e, path, j, i, "DATEOR", m[k])
 	}
 	return operDate, operEst
 
 }
+
+// OperationDateColumn returns the name of the column of the date of surgery,
+// the last column that ends with DATEOR or DATE_OR, or "" if there is none.
+func OperationDateColumn(keys []string) string {
+	column := ""
+	for _, k := range keys {
+		if strings.HasSuffix(k, "DATEOR") || strings.HasSuffix(k, "DATE_OR") {
+			column = k
+		}
+	}
+	return column
+}
@@ -0,0 +1,158 @@
+package excel2json
+
+import (
+	"excel/helper"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// InspectWorkbook writes how the excel file at path would be read to w: each sheet with
+// its name, its detected type and why, its header row, the columns that follow_up and operative
+// sheets are read from, the unexpected column names, and the events each row would produce.
+// o.Columns and o.Sheets are the columns file and the sheet classes file.
+// Returns the number of problems found, such as missing PTID columns.
+func InspectWorkbook(e *log.Logger, path string, o Options, w io.Writer) int {
+	opts = o
+	loadSheetClasses(e)
+	// the rows are read with the same aliases, vocabulary, organisms, rules and precedence as in a conversion
+	loadTables(e)
+	columnsChecker := opts.Columns
+	columns, err := helper.ReadLines(columnsChecker)
+	helper.CheckErr(e, err)
+
+	problems := 0
+	// false if a sheet would stop the conversion, then no events are read
+	readable := true
+	xlFile := openWorkbook(e, path)
+	fmt.Fprintln(w, "workbook:", path)
+	// j is the index of sheets
+	for j, sheet := range xlFile.Sheets {
+		sheetType, reason := helper.ClassifySheet(sheet, sheetClasses)
+		keys := helper.HeaderRow(sheet)
+		if sheetType == "" {
+			fmt.Fprintf(w, "sheet #%d %q: no sheet type, skipped (%s)\n", j+1, sheet.Name, reason)
+		} else {
+			fmt.Fprintf(w, "sheet #%d %q: %s sheet, %d rows (%s)\n", j+1, sheet.Name, sheetType, len(sheet.Rows)-1, reason)
+		}
+		fmt.Fprintln(w, "  headers:", strings.Join(keys, ", "))
+		if sheetType == "" {
+			continue
+		}
+		if unexpected := helper.UnexpectedColumns(keys, columns); len(unexpected) > 0 {
+			fmt.Fprintln(w, "  unexpected columns:", strings.Join(unexpected, ", "))
+			problems += len(unexpected)
+		}
+		if sheetType != "followup" && sheetType != "operative" {
+			fmt.Fprintln(w, "  no events are read from sheets of type", sheetType)
+			continue
+		}
+
+		// the same rules as CheckPtidColumns, a sheet can have any number of STATUS columns
+		ids := helper.PtidColumns(keys)
+		switch len(ids) {
+		case 0:
+			fmt.Fprintln(w, "  PTID columns: none, the conversion stops")
+			problems++
+			readable = false
+		case 1, 2:
+			fmt.Fprintln(w, "  PTID columns:", strings.Join(ids, ", "))
+		default:
+			fmt.Fprintln(w, "  PTID columns:", strings.Join(ids, ", "), "- more than two, the conversion stops")
+			problems++
+			readable = false
+		}
+		if sheetType == "operative" {
+			if k := helper.OperationDateColumn(keys); k != "" {
+				fmt.Fprintln(w, "  DATEOR column:", k)
+			} else {
+				fmt.Fprintln(w, "  DATEOR column: none, every row is a fix event")
+				problems++
+			}
+			for _, field := range []string{"surgeon", "surgeries", "periop_id", "notes"} {
+				if columns := classColumns(sheetType, field, operativeColumns, keys); len(columns) > 0 {
+					fmt.Fprintf(w, "  %s columns: %s\n", field, strings.Join(columns, ", "))
+				} else {
+					fmt.Fprintf(w, "  %s columns: none\n", field)
+				}
+			}
+			continue
+		}
+		status := helper.StatusColumns(keys)
+		if len(status) == 0 {
+			fmt.Fprintln(w, "  STATUS columns: none")
+		} else {
+			fmt.Fprintln(w, "  STATUS columns:", strings.Join(status, ", "))
+		}
+		if k := helper.OperationDateColumn(keys); k != "" {
+			fmt.Fprintln(w, "  DATEOR column:", k)
+		} else {
+			fmt.Fprintln(w, "  DATEOR column: none, stroke and tia events cannot be timed against the surgery")
+		}
+	}
+
+	if !readable {
+		fmt.Fprintln(w, "no events: the conversion would stop on this workbook")
+		return problems
+	}
+	problems += previewEvents(e, path, columnsChecker, w)
+	return problems
+}
+
+// previewEvents reads the excel file at path the same way as a conversion, and writes
+// to w the events each row of the follow_up and operative sheets would produce, before the duplicates of
+// other workbooks are merged. The events already read are kept apart and put back.
+// Returns the number of fix events.
+func previewEvents(e *log.Logger, path string, columnsChecker string, w io.Writer) int {
+	saved, savedOpts, savedWorkbooks := takeEvents(), opts, allWorkbooks
+	opts.Root = filepath.Dir(path)
+	ReadExcelData(e, path, nil, columnsChecker)
+	read, wb := takeEvents(), allWorkbooks[len(allWorkbooks)-1]
+	putEvents(read)
+	// the events of each row, by sheet and row number
+	type rowKey struct {
+		Sheet     int
+		SheetName string
+		Row       int
+	}
+	rows := map[rowKey][]string{}
+	for _, ev := range allEvents() {
+		text := ev.Type + " (no date)"
+		if ev.Date != nil && *ev.Date != "" {
+			text = ev.Type + " " + *ev.Date
+		}
+		for _, r := range ev.Source.Rows {
+			k := rowKey{r.Sheet, r.SheetName, r.Row}
+			rows[k] = append(rows[k], text)
+		}
+	}
+	for _, f := range allFix {
+		text := "fix " + f.EventType + ": " + f.Reason + " (" + f.Column + ")"
+		for _, r := range f.Source.Rows {
+			k := rowKey{r.Sheet, r.SheetName, r.Row}
+			rows[k] = append(rows[k], text)
+		}
+	}
+	fixes := len(allFix)
+	putEvents(saved)
+	opts, allWorkbooks = savedOpts, savedWorkbooks
+
+	keys := []rowKey{}
+	for k := range rows {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Sheet != keys[j].Sheet {
+			return keys[i].Sheet < keys[j].Sheet
+		}
+		return keys[i].Row < keys[j].Row
+	})
+	fmt.Fprintln(w, "events per row:", wb.Rows, "rows read")
+	for _, k := range keys {
+		fmt.Fprintf(w, "  sheet #%d %q row %d: %s\n", k.Sheet, k.SheetName, k.Row, strings.Join(rows[k], ", "))
+	}
+	return fixes
+}
@@ -0,0 +1,144 @@
+package excel2json
+
+import (
+	"excel/helper"
+	"excel/stats"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// the years of the survival tables
+var kmYears = []float64{1, 5, 10, 15}
+
+// the primary causes of death (PRM_DTH) of the cause-specific estimates
+var deathCauses = []struct {
+	Code int
+	Name string
+}{
+	{1, "valve-related death"},
+	{2, "cardiac, non valve-related death"},
+	{3, "non-cardiac death"},
+	{4, "dissection death"},
+}
+
+// a Kaplan-Meier estimate of the patients of the survival dataset
+type kmCurve struct {
+	Name   string // the name of the SVG file
+	Title  string
+	Times  []float64 // years from the index operation to the event or the end of follow-up
+	Events []bool
+	Points []stats.KMPoint
+}
+
+// kmCurves returns the Kaplan-Meier estimates of the survival, all-cause and for each primary
+// cause of death that patients died of, and of the freedom from each outcome of the survival dataset.
+// Deaths of the other causes are censored in the cause-specific estimates.
+func kmCurves(rows []survivalRow, level float64) []kmCurve {
+	years := func(days int) float64 { return float64(days) / 365.25 }
+	curves := []kmCurve{}
+	// always is false for the estimates that are left out if no patient had the event
+	add := func(name string, title string, always bool, event func(survivalRow) (bool, int)) {
+		c := kmCurve{Name: name, Title: title}
+		n := 0
+		for _, r := range rows {
+			ev, days := event(r)
+			c.Times = append(c.Times, years(days))
+			c.Events = append(c.Events, ev)
+			if ev {
+				n++
+			}
+		}
+		// leave out the cause-specific estimates of causes no patient died of
+		if n == 0 && !always {
+			return
+		}
+		c.Points = stats.KaplanMeier(c.Times, c.Events, level)
+		curves = append(curves, c)
+	}
+
+	add("survival", "Survival (all causes)", true, func(r survivalRow) (bool, int) {
+		return r.Death == 1, r.FollowupDays
+	})
+	for _, cause := range deathCauses {
+		code := cause.Code
+		add("death_prm_"+strconv.Itoa(code), "Freedom from "+cause.Name+" (PRM_DTH="+strconv.Itoa(code)+")", false,
+			func(r survivalRow) (bool, int) {
+				return r.Death == 1 && r.Cause == code, r.FollowupDays
+			})
+	}
+	for i, o := range survivalOutcomes {
+		k := i
+		add(o[0], "Freedom from "+o[0], true, func(r survivalRow) (bool, int) {
+			return r.Outcomes[k].Event == 1, r.Outcomes[k].Days
+		})
+	}
+	return curves
+}
+
+// writeKMText writes a survival table of each estimate at the years of kmYears.
+func writeKMText(w io.Writer, curves []kmCurve, level float64) {
+	for i, c := range curves {
+		events := 0
+		for _, ev := range c.Events {
+			if ev {
+				events++
+			}
+		}
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s: %d patients, %d events\n", c.Title, len(c.Times), events)
+		fmt.Fprintf(w, "  %5s %8s %9s   %g%% CI\n", "years", "at risk", "estimate", level*100)
+		for _, t := range kmYears {
+			p, ok := stats.SurvivalAt(c.Points, c.Times, t)
+			if !ok {
+				fmt.Fprintf(w, "  %5g %8d %9s   -\n", t, 0, "-")
+				continue
+			}
+			fmt.Fprintf(w, "  %5g %8d %8.1f%%   %.1f - %.1f\n", t, stats.AtRisk(c.Times, t),
+				p.Survival*100, p.Lower*100, p.Upper*100)
+		}
+	}
+}
+
+// writeKMPlots writes an SVG plot of each estimate to the folder dir.
+func writeKMPlots(dir string, curves []kmCurve) error {
+	for _, c := range curves {
+		last := 0.0
+		for _, t := range c.Times {
+			if t > last {
+				last = t
+			}
+		}
+		file, err := os.Create(filepath.Join(dir, c.Name+".svg"))
+		if err != nil {
+			return err
+		}
+		err = stats.PlotSVG(file, c.Title, c.Points, last)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReportKaplanMeier reads the events of a JSON file written by WriteToJSON and writes to w the
+// Kaplan-Meier estimates of the survival and of the freedom from each event at 1, 5, 10 and 15 years,
+// with Greenwood confidence intervals of the level. Time zero is the first operation of each patient.
+// An SVG plot of each estimate is written to the folder plotDir if it is not empty.
+// Returns the number of patients without an operation, who are left out.
+func ReportKaplanMeier(e *log.Logger, path string, level float64, w io.Writer, plotDir string) int {
+	loadOutput(e, path)
+	curves := kmCurves(survivalRows(e), level)
+	writeKMText(w, curves, level)
+	if plotDir != "" {
+		helper.CheckErr(e, os.MkdirAll(plotDir, 0755))
+		helper.CheckErr(e, writeKMPlots(plotDir, curves))
+	}
+	return leftOut(e, path)
+}
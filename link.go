@@ -0,0 +1,53 @@
+package excel2json
+
+import (
+	"fmt"
+	"sort"
+)
+
+// linkOperations sorts the operation events by patient and date and gives each one an id,
+// the PTID and its number among the operations of the patient by date, e.g. ABCD092780-2,
+// so the ids only change when operations of the same patient are added.
+// The parent of each operation is set to the most recent earlier operation of the
+// same patient, and its id is added to the children of that operation.
+func linkOperations() {
+	sort.SliceStable(allOperation, func(i, j int) bool {
+		a, b := allOperation[i], allOperation[j]
+		if a.PTID != b.PTID {
+			return a.PTID < b.PTID
+		} else if a.Date != b.Date {
+			return a.Date < b.Date
+		} else if a.Surgeon != b.Surgeon {
+			return a.Surgeon < b.Surgeon
+		}
+		return a.Source.Type < b.Source.Type
+	})
+	n := 0
+	for i := range allOperation {
+		o := &allOperation[i]
+		if i > 0 && allOperation[i-1].PTID == o.PTID {
+			n++
+		} else {
+			n = 1
+		}
+		o.ID = fmt.Sprintf("%s-%d", o.PTID, n)
+		o.Parent = nil
+		o.Children = nil
+	}
+	for i := range allOperation {
+		o := &allOperation[i]
+		if o.PTID == "" {
+			continue
+		}
+		// the operations of a patient are sorted by date,
+		// so the parent is the first earlier one before o
+		for k := i - 1; k >= 0 && allOperation[k].PTID == o.PTID; k-- {
+			if p := &allOperation[k]; p.Date < o.Date {
+				id := p.ID
+				o.Parent = &id
+				p.Children = append(p.Children, o.ID)
+				break
+			}
+		}
+	}
+}
@@ -5,35 +5,217 @@ import (
 	"excel/helper"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strings"
+	"time"
+)
+
+// exit codes
+const (
+	exitOK       = 0                  // no problems found
+	exitWarnings = 1                  // finished, but problems were found in the data
+	exitFailure  = helper.ExitFailure // stopped on an error or wrong usage
 )
 
 var (
-	folderPath string // path to the folder of excel files
-	errlogPath string // path to the error log file
-	jsonPath   string // path to the JSON file
+	folderPath  string // path to the folder of excel files
+	errlogPath  string // path to the error log file
+	jsonPath    string // path to the JSON file
+	columnsPath string // path to the columns file
+	legacyFix   bool   // write fix events with placeholder dates
+	rootPath    string // path to the root folder of the registry
+	htmlPath    string // path to the HTML data-quality report
+	statePath   string // path to the state file of incremental runs
+	sheetsPath  string // path to the sheet classes file
+	crosswalk   string // path to the crosswalk of PTID, MRN and research id
+	pivot       int    // century pivot of the birth years in PTIDs
+	aliasesPath string // path to the file of confirmed PTID aliases
+	dupsPath    string // path to the report of likely duplicate PTIDs
+	survival    string // path to the survival dataset
+	csvPath     string // path to the CSV file of a report
+	plotsPath   string // path to the folder of the SVG plots of the Kaplan-Meier report
+	earlyWindow string // days after an operation that events are early, or hospital
+	procsPath   string // path to the procedure vocabulary file
+	orgsPath    string // path to the organism table file
+	rulesPath   string // path to the rules file
+	precedence  string // the status codes from the highest precedence
+	indexOps    bool   // the DATEOR of each follow_up row is an operation event as well
+	validateOld bool   // -validate-only, the validate command before the commands existed
 )
 
-func init() {
+// the commands with their arguments and what they do
+var commands = [][3]string{
+	{"convert", "", "read the excel files of -folder and write the events to -json (the default command)"},
+	{"validate", "", "only check the excel files of -folder and print a data-quality scorecard of each workbook"},
+	{"diff", "old.json new.json", "report the added, removed and changed events per patient of two JSON files"},
+	{"inspect", "workbook.xlsx", "explain how a workbook is read: its sheets, their columns and the events of each row"},
+	{"columns", "", "check the header rows of the excel files of -folder against the columns file"},
+	{"report", "events.json", "print the linearized event rates per 100 patient-years of a JSON file, overall and by operation year"},
+	{"completeness", "events.json", "print the follow-up completeness of a JSON file up to -closing-date, per workbook, and the patients without recent contact"},
+	{"km", "events.json", "print the Kaplan-Meier survival and freedom-from-event estimates of a JSON file at 1, 5, 10 and 15 years"},
+}
 
-	// use command-line flags to assign paths
-	flag.StringVar(&folderPath, "folder", "", "a path to the folder")
-	flag.StringVar(&errlogPath, "errlog", "", "a path to the errorlog file")
-	flag.StringVar(&jsonPath, "json", "", "a path to the JSON file")
-	flag.Parse()
+// countWriter counts the messages written to the error log
+type countWriter struct {
+	w io.Writer
+	n int
+}
 
+func (c *countWriter) Write(p []byte) (int, error) {
+	c.n++
+	return c.w.Write(p)
 }
 
-func main() {
-	// open an error log file for writing and appending
+// usage prints the help text of the program.
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: main <command> [flags] [arguments]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", c[0], c[2])
+	}
+	fmt.Fprintln(os.Stderr, "\nrun \"main <command> -h\" for the flags of a command")
+	fmt.Fprintln(os.Stderr, "\nexit codes: 0 no problems, 1 problems found in the data, 2 stopped on an error")
+}
+
+// newCommand returns the flag set of a command, with the -errlog flag that all commands have.
+func newCommand(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		for _, c := range commands {
+			if c[0] == name {
+				fmt.Fprintln(os.Stderr, "usage: main", name, "[flags]", c[1])
+				fmt.Fprintln(os.Stderr, c[2])
+			}
+		}
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&errlogPath, "errlog", "", "a path to the errorlog file")
+	return fs
+}
+
+// addFolderFlags adds the flags of the commands that read the excel files of a folder.
+func addFolderFlags(fs *flag.FlagSet) {
+	fs.StringVar(&folderPath, "folder", "", "a path to the folder")
+	fs.StringVar(&columnsPath, "columns", "", "a path to the columns file (default: ask for it)")
+	fs.StringVar(&rootPath, "root", "", "a path to the root folder that source paths are relative to (default: the -folder path)")
+	addSheetsFlag(fs)
+}
+
+// addSheetsFlag adds the flag of the sheet classes file.
+func addSheetsFlag(fs *flag.FlagSet) {
+	fs.StringVar(&sheetsPath, "sheets", "", "a path to a JSON file of sheet classes that detect the sheet types (default: follow_up sheets only)")
+}
+
+// addReadFlags adds the flags that change how the rows of the excel files are read,
+// for the commands that read them as a conversion does.
+func addReadFlags(fs *flag.FlagSet) {
+	fs.StringVar(&aliasesPath, "aliases", "", "a path to a CSV (ALIAS, PTID columns) or JSON file of confirmed PTID aliases, replaced by their PTIDs when reading")
+	fs.StringVar(&procsPath, "procedures", "", "a path to a JSON procedure vocabulary that the surgeries of re-operations (REOPSURG) are parsed with")
+	fs.StringVar(&precedence, "status-precedence", "", "the status codes from the highest precedence, separated by commas, that the STATUS columns are reconciled with when they disagree (default D,N,L,O,A,R)")
+	fs.StringVar(&rulesPath, "rules", "", "a path to a JSON rules file, checks of the cells of each row written as expressions, e.g. DIED == 1 && empty(DTH_D)")
+	fs.StringVar(&orgsPath, "organisms", "", "a path to a JSON organism table with the canonical names, groups and synonyms of the SBE organisms (default: the built-in table)")
+	fs.BoolVar(&indexOps, "index-operations", false, "create an operation event from the DATEOR of each follow_up row, the index operation that re-operations and events are timed against")
+}
+
+// openErrlog opens the error log file for writing and appending,
+// and returns a logger e and the counter of its messages.
+func openErrlog() (*log.Logger, *countWriter, *os.File) {
 	errLog, err := os.OpenFile(errlogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
 		fmt.Println(err)
 	}
-	defer errLog.Close()
+	c := &countWriter{w: errLog}
 	// create a new logger e
-	e := log.New(errLog, "ERROR: ", 0)
+	e := log.New(c, "ERROR: ", 0)
+	return e, c, errLog
+}
+
+// exitCode returns exitWarnings if problems were found, else exitOK.
+func exitCode(problems int) int {
+	if problems > 0 {
+		return exitWarnings
+	}
+	return exitOK
+}
+
+func main() {
+	args := os.Args[1:]
+	// without a command, run convert as before the commands existed
+	name := "convert"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	switch name {
+	case "convert":
+		os.Exit(convert(args, false))
+	case "validate":
+		os.Exit(convert(args, true))
+	case "diff":
+		os.Exit(diff(args))
+	case "inspect":
+		os.Exit(inspect(args))
+	case "columns":
+		os.Exit(columns(args))
+	case "report":
+		os.Exit(report(args))
+	case "km":
+		os.Exit(km(args))
+	case "completeness":
+		os.Exit(completeness(args))
+	case "help":
+		usage()
+		os.Exit(exitOK)
+	}
+	fmt.Fprintln(os.Stderr, "unknown command:", name)
+	usage()
+	os.Exit(exitFailure)
+}
+
+// convert reads the excel files and writes the events to the JSON file,
+// or only prints the data-quality scorecards when validating.
+func convert(args []string, validate bool) int {
+	fs := newCommand("convert")
+	if validate {
+		fs = newCommand("validate")
+	}
+	addFolderFlags(fs)
+	fs.StringVar(&htmlPath, "html", "", "a path to write an HTML data-quality report to")
+	fs.StringVar(&crosswalk, "crosswalk", "", "a path to a CSV (PTID, MRN, RESEARCH_ID columns) or JSON crosswalk that sets the MRN and research id of the events")
+	fs.IntVar(&pivot, "century-pivot", 20, "two-digit birth years in PTIDs up to this one are in the 2000s, the others in the 1900s")
+	fs.StringVar(&earlyWindow, "early-window", "30", "events up to this many days after the nearest preceding operation are early, or \"hospital\" for events up to its discharge date")
+	addReadFlags(fs)
+	fs.StringVar(&dupsPath, "duplicates", "", "a path to write a CSV report of PTIDs that are likely typos of each other")
+	if !validate {
+		fs.StringVar(&jsonPath, "json", "", "a path to the JSON file")
+		fs.BoolVar(&legacyFix, "legacy-fix", false, "write fix events in the old format with placeholder dates")
+		fs.StringVar(&statePath, "state", "", "a path to a state file, only the workbooks that changed since the last run are read again")
+		fs.StringVar(&survival, "survival", "", "a path to write the survival dataset to, one row per patient, Parquet if it ends with .parquet, else CSV")
+		fs.BoolVar(&validateOld, "validate-only", false, "deprecated, use the validate command")
+	}
+	fs.Parse(args)
+	// -validate-only still runs validate
+	if validateOld {
+		fmt.Fprintln(os.Stderr, "-validate-only is deprecated, use: main validate")
+		validate = true
+	}
+
+	e, logged, errLog := openErrlog()
+	defer errLog.Close()
+
+	options := excel2json.Options{LegacyFix: legacyFix, Root: rootPath, ValidateOnly: validate,
+		HTMLPath: htmlPath, StatePath: statePath, Columns: columnsPath, Sheets: sheetsPath,
+		Crosswalk: crosswalk, CenturyPivot: pivot, Aliases: aliasesPath, Duplicates: dupsPath,
+		Survival: survival, EarlyWindow: earlyWindow, Procedures: procsPath,
+		Organisms: orgsPath, Rules: rulesPath, Precedence: precedence, IndexOps: indexOps}
+
+	// when validating, only read the excel files and print the scorecards
+	if validate {
+		excel2json.LoopAllFiles(e, folderPath, nil, options)
+		helper.Close(e, errlogPath)
+		return exitCode(logged.n + excel2json.Warnings())
+	}
 
 	// open a JSON file for further writing and appending
 	jsonFile, err := os.OpenFile(jsonPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
@@ -42,10 +224,129 @@ func main() {
 
 	// loop through the excel files from folderPath,
 	// using logger e to record error messages, and writes events to jsonFile
-	excel2json.LoopAllFiles(e, folderPath, jsonFile)
+	excel2json.LoopAllFiles(e, folderPath, jsonFile, options)
 
 	// close the JSON file and error logs
 	helper.Close(e, jsonPath)
 	helper.Close(e, errlogPath)
+	return exitCode(logged.n + excel2json.Warnings())
+}
+
+// diff compares two JSON files, the differences are written to -json if it is set.
+func diff(args []string) int {
+	fs := newCommand("diff")
+	fs.StringVar(&jsonPath, "json", "", "a path to write the differences as JSON to")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return exitFailure
+	}
+
+	e, _, errLog := openErrlog()
+	defer errLog.Close()
+
+	var jsonFile *os.File
+	if jsonPath != "" {
+		var err error
+		jsonFile, err = os.OpenFile(jsonPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+		helper.CheckErr(e, err)
+		defer jsonFile.Close()
+	}
+	return exitCode(excel2json.DiffFiles(e, fs.Arg(0), fs.Arg(1), jsonFile))
+}
+
+// report prints the linearized event rates of a JSON file, they are also written to -csv if it is set.
+func report(args []string) int {
+	fs := newCommand("report")
+	// the confidence level of the intervals
+	var confidence float64
+	fs.StringVar(&csvPath, "csv", "", "a path to write the rates as CSV to")
+	fs.Float64Var(&confidence, "confidence", 0.95, "the confidence level of the intervals")
+	fs.Parse(args)
+	if fs.NArg() != 1 || confidence <= 0 || confidence >= 1 {
+		fs.Usage()
+		return exitFailure
+	}
+
+	e, _, errLog := openErrlog()
+	defer errLog.Close()
+	return exitCode(excel2json.ReportRates(e, fs.Arg(0), confidence, os.Stdout, csvPath))
+}
+
+// km prints the Kaplan-Meier estimates of a JSON file, their plots are written to -plots if it is set.
+func km(args []string) int {
+	fs := newCommand("km")
+	// the confidence level of the intervals
+	var confidence float64
+	fs.StringVar(&plotsPath, "plots", "", "a path to a folder to write an SVG plot of each estimate to")
+	fs.Float64Var(&confidence, "confidence", 0.95, "the confidence level of the intervals")
+	fs.Parse(args)
+	if fs.NArg() != 1 || confidence <= 0 || confidence >= 1 {
+		fs.Usage()
+		return exitFailure
+	}
 
+	e, _, errLog := openErrlog()
+	defer errLog.Close()
+	return exitCode(excel2json.ReportKaplanMeier(e, fs.Arg(0), confidence, os.Stdout, plotsPath))
+}
+
+// completeness prints the follow-up completeness of a JSON file, the completeness of
+// each patient is written to -csv if it is set.
+func completeness(args []string) int {
+	fs := newCommand("completeness")
+	// the study closing date and the days without contact after which a patient is stale
+	var closing string
+	var staleDays int
+	fs.StringVar(&closing, "closing-date", time.Now().Format("2006-01-02"), "the study closing date, YYYY-MM-DD")
+	fs.IntVar(&staleDays, "stale-days", 365, "list the patients alive at their last contact more than this many days before the closing date")
+	fs.StringVar(&csvPath, "csv", "", "a path to write the completeness of each patient as CSV to")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitFailure
+	}
+
+	e, _, errLog := openErrlog()
+	defer errLog.Close()
+	return exitCode(excel2json.ReportCompleteness(e, fs.Arg(0), closing, staleDays, os.Stdout, csvPath))
+}
+
+// inspect shows how one workbook would be read.
+func inspect(args []string) int {
+	fs := newCommand("inspect")
+	fs.StringVar(&columnsPath, "columns", "", "a path to the columns file (default: ask for it)")
+	addSheetsFlag(fs)
+	addReadFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitFailure
+	}
+
+	e, _, errLog := openErrlog()
+	defer errLog.Close()
+	if columnsPath == "" {
+		columnsPath = helper.ReadUserInput()
+	}
+	options := excel2json.Options{Columns: columnsPath, Sheets: sheetsPath, Aliases: aliasesPath,
+		Procedures: procsPath, Organisms: orgsPath, Rules: rulesPath, Precedence: precedence, IndexOps: indexOps}
+	return exitCode(excel2json.InspectWorkbook(e, fs.Arg(0), options, os.Stdout))
+}
+
+// columns checks the header rows of the excel files against the columns file.
+func columns(args []string) int {
+	fs := newCommand("columns")
+	fs.StringVar(&folderPath, "folder", "", "a path to the folder")
+	fs.StringVar(&columnsPath, "columns", "", "a path to the columns file (default: ask for it)")
+	addSheetsFlag(fs)
+	fs.Parse(args)
+
+	e, _, errLog := openErrlog()
+	defer errLog.Close()
+	if columnsPath == "" {
+		columnsPath = helper.ReadUserInput()
+	}
+	options := excel2json.Options{Columns: columnsPath, Sheets: sheetsPath}
+	return exitCode(excel2json.AuditColumns(e, folderPath, options, os.Stdout))
 }
@@ -0,0 +1,174 @@
+package excel2json
+
+import (
+	"excel/helper"
+	"log"
+	"strings"
+)
+
+// column patterns of the fields of index operations when the sheet class does not set them
+var operativeColumns = map[string][]string{
+	"surgeon":   {"SURGEON"},
+	"surgeries": {"SURGERY", "PROCEDURE"},
+	"periop_id": {"PERIOP"},
+	"notes":     {"NOTES"},
+	"discharge": {"DISCH"},
+}
+
+// classColumns returns the columns of keys that match the column patterns of a field
+// for the sheets of a type, the patterns of the sheet class or else the defaults.
+func classColumns(sheetType string, field string, defaults map[string][]string, keys []string) []string {
+	patterns := defaults[field]
+	for _, c := range sheetClasses {
+		if c.Type == sheetType && c.Columns[field] != nil {
+			patterns = c.Columns[field]
+			break
+		}
+	}
+	columns := []string{}
+	for _, k := range keys {
+		if helper.StringInSlice(1, k, patterns) {
+			columns = append(columns, k)
+		}
+	}
+	return columns
+}
+
+// readOperativeSheet creates the index operation events of the rows s of an operative registry sheet,
+// and stores them in the slices. path is the sub path of the excel file, j is the index of the sheet
+// and keys is its header row. An operation of a follow_up sheet with the same PTID and date
+// is the same surgery, the two are merged.
+func readOperativeSheet(e *log.Logger, path string, sheetName string, j int, s []map[string]string, keys []string) {
+	// p1, p2 is the PTID column names
+	p1, p2 := helper.CheckPtidColumns(e, path, j, keys)
+	// the column of the date of surgery
+	dateCol := helper.OperationDateColumn(keys)
+	if dateCol == "" {
+		e.Println(path, "Sheet #:", j+1, "INFO: This operative sheet does not have a DATEOR column!")
+	}
+	surgeonCols := classColumns("operative", "surgeon", operativeColumns, keys)
+	surgeryCols := classColumns("operative", "surgeries", operativeColumns, keys)
+	periopCols := classColumns("operative", "periop_id", operativeColumns, keys)
+	notesCols := classColumns("operative", "notes", operativeColumns, keys)
+	dischargeCols := classColumns("operative", "discharge", operativeColumns, keys)
+	// the columns that operation events use
+	used := append([]string{dateCol}, surgeonCols...)
+	used = append(used, surgeryCols...)
+	used = append(used, periopCols...)
+	used = append(used, notesCols...)
+	used = append(used, dischargeCols...)
+
+	// i is the index of rows
+	// m is the map representing the correspnding row with the index i
+	for i, m := range s {
+		// check PTID
+		ID1, ID2 := m[p1], m[p2]
+		// replace the confirmed aliases by their PTIDs
+		ID1, ID2 = resolveAlias(ID1), resolveAlias(ID2)
+		// assign PTIDs
+		diffID := helper.AssignPTID(&ID1, &ID2)
+
+		// r is the row that the events of this row come from
+		r := newRowRef(path, sheetName, j, i, m, p1, p2)
+
+		date, est := helper.CheckDateFormat(e, path, j, i, "DATEOR", m[dateCol])
+		// check if format of PTID is LLLFDDMMYY
+		helper.CheckPtidFormat(ID1, date, e, path, j, i)
+
+		op := operation{
+			PTID:    ID1,
+			Type:    "operation",
+			Date:    date,
+			DateEst: est,
+			Source:  newSource(r, m, used...)}
+		op.Source.Type = "operative"
+		// the surgeon, the surgeries, the perioperative id and the notes
+		op.Surgeon = strings.TrimSpace(firstValue(m, surgeonCols))
+		// the surgeries are parsed the same way as the surgeries of a re-operation
+		surgeryText := false
+		for _, c := range surgeryCols {
+			if strings.TrimSpace(m[c]) == "" {
+				continue
+			}
+			surgeryText = true
+			list, msg := parseSurgeries(m[c])
+			op.Surgeries = append(op.Surgeries, list...)
+			if msg != nil {
+				op.Fix = append(op.Fix, *msg)
+			}
+		}
+		if v := strings.TrimSpace(firstValue(m, periopCols)); v != "" {
+			op.PeriopID = &v
+		}
+		if v := strings.TrimSpace(firstValue(m, notesCols)); v != "" {
+			op.Notes = &v
+		}
+		// the discharge date, for events in hospital
+		if v := firstValue(m, dischargeCols); strings.TrimSpace(v) != "" {
+			d, dEst := helper.CheckDateFormat(e, path, j, i, "DISCHARGE", v)
+			if dEst == 0 || dEst == 1 {
+				op.Discharge = &d
+			} else {
+				op.Fix = append(op.Fix, errMessage{"discharge_date", "invalid value: '" + v + "'"})
+			}
+		}
+		// an empty row
+		if ID1 == "" && est == 2 && op.Surgeon == "" && !surgeryText && op.PeriopID == nil {
+			continue
+		}
+		// check the row against the rules of the rules file
+		checkRules(e, path, "operative", j, i, keys, m, ID1, r)
+		// two PTIDs, create a fix event
+		if diffID {
+			f := newFix(ID1, "row", fixConflictingSources, r, m, p1, p2)
+			f.Source.Type = "operative"
+			f.Msg = "two different PTIDs: '" + ID1 + "', '" + ID2 + "'"
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
+
+		// operation date with valid format
+		if est == 0 || est == 1 {
+			// check PTID
+			if diffID {
+				msg := errMessage{"patient_id", "two different PTIDs: '" + ID1 + "', '" + ID2 + "' "}
+				op.Fix = append(op.Fix, msg)
+			}
+			// if no duplicates, store in a slice
+			if !op.CompareOperation(allOperation) {
+				allOperation = append(allOperation, op)
+			}
+			// invalid or empty date format, create a fix event
+		} else {
+			f := newFix(ID1, "operation", fixReason(est), r, m, used...)
+			f.Source.Type = "operative"
+			if est == 3 {
+				f.Msg = "Invalid date of surgery format: '" + date + "'"
+			} else {
+				f.Msg = "Operation without date of surgery"
+			}
+			if op.Surgeon != "" {
+				f.Msg += ", surgeon: " + op.Surgeon
+			}
+			if op.Surgeries != nil {
+				f.Msg += ", surgeries: " + strings.Join(op.Surgeries, "; ")
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
+	}
+}
+
+// firstValue returns the first non-empty value of the columns of the row m.
+func firstValue(m map[string]string, columns []string) string {
+	for _, c := range columns {
+		if m[c] != "" {
+			return m[c]
+		}
+	}
+	return ""
+}
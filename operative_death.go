@@ -0,0 +1,50 @@
+package excel2json
+
+import (
+	"fmt"
+)
+
+// a death up to this many days after an operation is operative
+const operativeDays = 30
+
+// setOperative sets the operative field of every death event from the nearest operation of the
+// patient on or before the death, re-operations and the DATEOR of follow_up rows included, with
+// or without -index-operations: the death is operative if it is up to operativeDays days after
+// the operation, or on or before its discharge date when it has one. The operation and the rule
+// are kept as the evidence of the death. A death whose coded operative (SURVIVAL) disagrees gets
+// a fix message, and without an operation the coded value is kept.
+func setOperative() {
+	for i := range allDths {
+		d := &allDths[i]
+		d.Evidence = nil
+		op := nearestOperation(d.PTID, d.Date)
+		if op == nil {
+			if d.CodedOp != nil && *d.CodedOp == 1 {
+				msg := errMessage{"operative", "SURVIVAL codes an operative death, but there is no operation on or before the death"}
+				d.Fix = append(d.Fix, msg)
+			}
+			continue
+		}
+		ev := opEvidence{OperationID: op.ID, OperationDate: op.Date, DaysAfter: daysBetween(op.Date, d.Date), Discharge: op.Discharge}
+		ev.Within30Days = ev.DaysAfter <= operativeDays
+		ev.BeforeDischarge = op.Discharge != nil && d.Date <= *op.Discharge
+		d.Evidence = &ev
+		d.Operative = 0
+		if ev.Within30Days || ev.BeforeDischarge {
+			d.Operative = 1
+		}
+
+		if d.CodedOp != nil && *d.CodedOp != d.Operative {
+			coded, derived := "not operative", "operative"
+			if *d.CodedOp == 1 {
+				coded, derived = derived, coded
+			}
+			msg := fmt.Sprintf("SURVIVAL codes the death as %s, but it is %s: %d days after the operation of '%s'",
+				coded, derived, ev.DaysAfter, op.Date)
+			if op.Discharge != nil {
+				msg += ", discharged on '" + *op.Discharge + "'"
+			}
+			d.Fix = append(d.Fix, errMessage{"operative", msg})
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package excel2json
+
+import (
+	"encoding/json"
+	"excel/helper"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// an organism of the organism table, its group and the other names it is written as
+type organism struct {
+	Name     string   `json:"name"`
+	Group    string   `json:"group"` // staphylococcal, streptococcal, enterococcal, culture-negative, fungal or other
+	Synonyms []string `json:"synonyms"`
+}
+
+// the organism table when no organisms file is set
+var defaultOrganisms = []organism{
+	{"Staphylococcus aureus", "staphylococcal", []string{"S aureus", "Staph aureus", "Staphylococcus aureus", "MSSA", "MRSA"}},
+	{"Coagulase-negative staphylococci", "staphylococcal", []string{"CoNS", "Coag neg staph", "Coagulase negative staph",
+		"S epidermidis", "Staph epidermidis", "Staphylococcus epidermidis"}},
+	{"Viridans group streptococci", "streptococcal", []string{"Strep viridans", "Viridans strep", "S viridans",
+		"Streptococcus viridans", "Viridans", "Viridans streptococci"}},
+	{"Streptococcus gallolyticus", "streptococcal", []string{"S bovis", "Strep bovis", "Streptococcus bovis", "S gallolyticus"}},
+	{"Enterococcus faecalis", "enterococcal", []string{"E faecalis", "Enterococcus faecalis", "Strep faecalis"}},
+	{"Enterococcus faecium", "enterococcal", []string{"E faecium", "Enterococcus faecium"}},
+	{"Enterococcus species", "enterococcal", []string{"Enterococcus", "Enterococci", "Entero"}},
+	{"Culture-negative", "culture-negative", []string{"Culture negative", "Culture neg", "Negative", "Neg cultures", "No growth", "CNE"}},
+	{"Candida species", "fungal", []string{"Candida", "Candida albicans", "C albicans"}},
+	{"Aspergillus species", "fungal", []string{"Aspergillus", "Fungal", "Fungus"}},
+}
+
+// the canonical organism of each normalized name
+var organismNames map[string]*organism
+
+// the characters that are not part of the words of a name
+var notWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// normalizeName returns the name in lower case, without punctuation and with single spaces,
+// so that "S. aureus" and "s aureus" are the same.
+func normalizeName(s string) string {
+	s = notWord.ReplaceAllString(strings.ToLower(s), " ")
+	return strings.TrimSpace(s)
+}
+
+// loadOrganisms reads the organism table from the JSON file of opts.Organisms, a list of objects
+// with the name, the group and the synonyms of an organism, or uses the default table.
+func loadOrganisms(e *log.Logger) {
+	table := defaultOrganisms
+	if opts.Organisms != "" {
+		file, err := os.Open(opts.Organisms)
+		helper.CheckErr(e, err)
+		defer file.Close()
+		table = nil
+		helper.CheckErr(e, json.NewDecoder(file).Decode(&table))
+	}
+	organismNames = map[string]*organism{}
+	for i := range table {
+		o := &table[i]
+		for _, name := range append([]string{o.Name}, o.Synonyms...) {
+			organismNames[normalizeName(name)] = o
+		}
+	}
+}
+
+// normalizeOrganism sets the canonical name and the group of the organism of the sbe event s
+// from the organism table, the raw value is kept. It runs before the duplicates are merged, so
+// that two names of the same organism are the same event. An organism that is not in the table
+// gets a fix message.
+func (s *general) normalizeOrganism() {
+	s.Species, s.Group = nil, nil
+	if s.Organism == nil {
+		return
+	}
+	if o, ok := organismNames[normalizeName(*s.Organism)]; ok {
+		name, group := o.Name, o.Group
+		s.Species, s.Group = &name, &group
+	} else {
+		s.Fix = append(s.Fix, errMessage{"organism", "organism not in the organism table: '" + *s.Organism + "'"})
+	}
+}
@@ -0,0 +1,73 @@
+package excel2json
+
+import (
+	"encoding/json"
+	"excel/helper"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// a procedure of the vocabulary and the patterns of its names in the free text
+type procedure struct {
+	Name     string   `json:"name"`
+	Patterns []string `json:"patterns"` // regular expressions, matched as whole words, ignoring case
+	regs     []*regexp.Regexp
+}
+
+// the procedure vocabulary that the surgeries of re-operations are parsed with
+var procedures []procedure
+
+// loadProcedures reads the procedure vocabulary from the JSON file of opts.Procedures,
+// a list of objects with the name of a procedure and the patterns of its names, e.g.
+// [{"name": "AVR", "patterns": ["AVR", "aortic valve replacement"]}]
+func loadProcedures(e *log.Logger) {
+	procedures = nil
+	if opts.Procedures == "" {
+		return
+	}
+	file, err := os.Open(opts.Procedures)
+	helper.CheckErr(e, err)
+	defer file.Close()
+	helper.CheckErr(e, json.NewDecoder(file).Decode(&procedures))
+	for i := range procedures {
+		p := &procedures[i]
+		if len(p.Patterns) == 0 {
+			p.Patterns = []string{regexp.QuoteMeta(p.Name)}
+		}
+		for _, pattern := range p.Patterns {
+			reg, err := regexp.Compile(`(?i)\b(?:` + pattern + `)\b`)
+			helper.CheckErr(e, err)
+			p.regs = append(p.regs, reg)
+		}
+	}
+}
+
+// parseSurgeries returns the surgeries of the free text of a re-operation or of a surgery column
+// of an operative sheet: the names of the procedures of the vocabulary that it mentions, in the
+// order of the vocabulary, and a fix message if it mentions none. Without a vocabulary the text
+// is split on ";".
+func parseSurgeries(text string) ([]string, *errMessage) {
+	list := []string{}
+	if procedures == nil {
+		for _, v := range strings.Split(text, ";") {
+			if v = strings.TrimSpace(v); v != "" {
+				list = append(list, v)
+			}
+		}
+		return list, nil
+	}
+	for _, p := range procedures {
+		for _, reg := range p.regs {
+			if reg.MatchString(text) {
+				list = append(list, p.Name)
+				break
+			}
+		}
+	}
+	if len(list) == 0 {
+		return nil, &errMessage{"surgeries", "no procedure of the vocabulary in: '" + text + "'"}
+	}
+	return list, nil
+}
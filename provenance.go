@@ -0,0 +1,42 @@
+package excel2json
+
+// newRowRef returns the reference of the row with index i in the sheet with index j,
+// path is the sub path of the excel file, sheetName is the name of the sheet and m is the row.
+// The cells of columns that exist in the row are kept, they are used by all events of the row.
+func newRowRef(path string, sheetName string, j int, i int, m map[string]string, columns ...string) rowRef {
+	r := rowRef{
+		Path:      path,
+		SheetName: sheetName,
+		Sheet:     j + 1,
+		Row:       i + 2, // the header row is row 1
+		Columns:   map[string]string{}}
+	for _, c := range columns {
+		if v, ok := m[c]; ok {
+			r.Columns[c] = v
+		}
+	}
+	return r
+}
+
+// newSource returns the source of an event created from the row r,
+// m is the row and columns are the names of the columns that the event uses.
+func newSource(r rowRef, m map[string]string, columns ...string) source {
+	// copy the cells of r, so events of the same row don't share the map
+	cells := map[string]string{}
+	for c, v := range r.Columns {
+		cells[c] = v
+	}
+	for _, c := range columns {
+		if v, ok := m[c]; ok {
+			cells[c] = v
+		}
+	}
+	r.Columns = cells
+	return source{Type: "followup", Path: []string{r.Path}, Rows: []rowRef{r}}
+}
+
+// add adds the paths and rows of o, the source of a duplicate event, to s.
+func (s *source) add(o source) {
+	s.Path = append(s.Path, o.Path...)
+	s.Rows = append(s.Rows, o.Rows...)
+}
@@ -0,0 +1,274 @@
+package excel2json
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"excel/helper"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// confirmed aliases of PTIDs, the PTID each alias is replaced with when the rows are read
+var aliases map[string]string
+
+// loadAliases reads the aliases file of opts.Aliases, a JSON file (*.json) of an object
+// of alias to PTID, or else a CSV file with a header row that has the columns ALIAS and PTID.
+func loadAliases(e *log.Logger) {
+	aliases = map[string]string{}
+	if opts.Aliases == "" {
+		return
+	}
+	file, err := os.Open(opts.Aliases)
+	helper.CheckErr(e, err)
+	defer file.Close()
+
+	if strings.HasSuffix(strings.ToLower(opts.Aliases), ".json") {
+		helper.CheckErr(e, json.NewDecoder(file).Decode(&aliases))
+		return
+	}
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+	header, err := r.Read()
+	helper.CheckErr(e, err)
+	alias, ptid := -1, -1
+	for i, h := range header {
+		switch strings.ToUpper(strings.TrimSpace(h)) {
+		case "ALIAS":
+			alias = i
+		case "PTID", "PATIENT_ID":
+			ptid = i
+		}
+	}
+	if alias < 0 || ptid < 0 {
+		helper.CheckErr(e, errors.New(opts.Aliases+": the aliases file needs the columns ALIAS and PTID"))
+	}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		helper.CheckErr(e, err)
+		if alias < len(record) && ptid < len(record) {
+			aliases[strings.TrimSpace(record[alias])] = strings.TrimSpace(record[ptid])
+		}
+	}
+}
+
+// resolveAlias returns the PTID that id is an alias of, or id if it is not an alias.
+func resolveAlias(id string) string {
+	// follow chains of aliases, at most once through each alias
+	for n := 0; n < len(aliases); n++ {
+		ptid, ok := aliases[strings.TrimSpace(id)]
+		if !ok || ptid == id {
+			break
+		}
+		id = ptid
+	}
+	return id
+}
+
+// a PTID and what the events of the PTID tell about it
+type ptidInfo struct {
+	PTID       string
+	Events     int
+	Workbooks  []string
+	Operations []string // dates of the operations
+}
+
+// a likely duplicate PTID in the report
+type duplicate struct {
+	Cluster    int      `json:"cluster"`
+	PTID       string   `json:"patient_id"`
+	Suggested  string   `json:"suggested_patient_id"` // the PTID of the cluster with the most events
+	Distance   int      `json:"distance"`             // edit distance to the suggested PTID
+	SameBirth  bool     `json:"same_birth_date"`      // the MMDDYY part is the same as the suggested PTID's
+	SharedOps  []string `json:"shared_operation_dates"`
+	Events     int      `json:"events"`
+	Workbooks  []string `json:"workbooks"`
+	Operations []string `json:"operation_dates"`
+}
+
+// deletions returns s and the strings that are s with one or two runes deleted.
+// Two strings within an edit distance of two share at least one of them.
+func deletions(s string) []string {
+	list := []string{s}
+	r := []rune(s)
+	for i := range r {
+		one := string(append(append([]rune{}, r[:i]...), r[i+1:]...))
+		list = append(list, one)
+		o := []rune(one)
+		for j := i; j < len(o); j++ {
+			list = append(list, string(append(append([]rune{}, o[:j]...), o[j+1:]...)))
+		}
+	}
+	return list
+}
+
+// birthPart returns the birth date that the MMDDYY part of a PTID with the format LLLFMMDDYY
+// encodes, so that a month or a day without leading zero is the same, or "".
+func birthPart(id string) string {
+	date, _ := helper.BirthDate(id, 0)
+	return date
+}
+
+// findDuplicates groups the PTIDs of all events that are likely the same patient. Two PTIDs are
+// linked if their edit distance is one or two, and they have the same birth date part or a shared
+// operation date, the DATEOR of follow_up rows included. Returns the clusters of more than one PTID.
+func findDuplicates() []duplicate {
+	infos := map[string]*ptidInfo{}
+	info := func(id string) *ptidInfo {
+		if infos[id] == nil {
+			infos[id] = &ptidInfo{PTID: id}
+		}
+		return infos[id]
+	}
+	for _, ev := range allEvents() {
+		id := strings.TrimSpace(*ev.PTID)
+		if id == "" {
+			continue
+		}
+		p := info(id)
+		p.Events++
+		for _, path := range ev.Source.Path {
+			p.Workbooks = appendNew(p.Workbooks, path)
+		}
+		if ev.Type == "operation" {
+			p.Operations = appendNew(p.Operations, *ev.Date)
+		}
+	}
+	// the index operations that are not operation events
+	for _, o := range allIndexOps {
+		if id := strings.TrimSpace(o.PTID); id != "" {
+			info(id).Operations = appendNew(info(id).Operations, o.Date)
+		}
+	}
+	ids := []string{}
+	for id := range infos {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	// union-find of the indexes of ids
+	parent := make([]int, len(ids))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	// only compare the PTIDs that share a deletion variant
+	variants := map[string][]int{}
+	for i, id := range ids {
+		for _, v := range deletions(id) {
+			if l := variants[v]; len(l) == 0 || l[len(l)-1] != i {
+				variants[v] = append(l, i)
+			}
+		}
+	}
+	compared := map[[2]int]bool{}
+	for _, l := range variants {
+		for x := 0; x < len(l); x++ {
+			for y := x + 1; y < len(l); y++ {
+				i, j := l[x], l[y]
+				if compared[[2]int{i, j}] {
+					continue
+				}
+				compared[[2]int{i, j}] = true
+				a, b := infos[ids[i]], infos[ids[j]]
+				d := helper.EditDistance(a.PTID, b.PTID)
+				sameBirth := birthPart(a.PTID) != "" && birthPart(a.PTID) == birthPart(b.PTID)
+				if d <= 2 && (sameBirth || len(shared(a.Operations, b.Operations)) > 0) {
+					parent[find(i)] = find(j)
+				}
+			}
+		}
+	}
+
+	clusters := map[int][]int{}
+	for i := range ids {
+		clusters[find(i)] = append(clusters[find(i)], i)
+	}
+	roots := []int{}
+	for r, members := range clusters {
+		if len(members) > 1 {
+			roots = append(roots, r)
+		}
+	}
+	sort.Slice(roots, func(x, y int) bool { return clusters[roots[x]][0] < clusters[roots[y]][0] })
+
+	list := []duplicate{}
+	for c, r := range roots {
+		members := clusters[r]
+		// suggest the PTID with the most events
+		best := infos[ids[members[0]]]
+		for _, i := range members {
+			if infos[ids[i]].Events > best.Events {
+				best = infos[ids[i]]
+			}
+		}
+		for _, i := range members {
+			p := infos[ids[i]]
+			list = append(list, duplicate{
+				Cluster:    c + 1,
+				PTID:       p.PTID,
+				Suggested:  best.PTID,
+				Distance:   helper.EditDistance(p.PTID, best.PTID),
+				SameBirth:  birthPart(p.PTID) != "" && birthPart(p.PTID) == birthPart(best.PTID),
+				SharedOps:  shared(p.Operations, best.Operations),
+				Events:     p.Events,
+				Workbooks:  p.Workbooks,
+				Operations: p.Operations})
+		}
+	}
+	return list
+}
+
+// shared returns the strings that are in both a and b.
+func shared(a []string, b []string) []string {
+	list := []string{}
+	for _, s := range a {
+		if helper.StringInSlice(0, s, b) {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// writeDuplicates writes the likely duplicate PTIDs to a CSV file, one row per PTID,
+// the PTIDs of a cluster are likely the same patient.
+func writeDuplicates(path string, list []duplicate) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+	w.Write([]string{"CLUSTER", "PTID", "SUGGESTED_PTID", "DISTANCE", "SAME_BIRTH_DATE",
+		"SHARED_OPERATION_DATES", "EVENTS", "WORKBOOKS", "OPERATION_DATES"})
+	for _, d := range list {
+		w.Write([]string{strconv.Itoa(d.Cluster), d.PTID, d.Suggested, strconv.Itoa(d.Distance),
+			strconv.FormatBool(d.SameBirth), strings.Join(d.SharedOps, "; "), strconv.Itoa(d.Events),
+			strings.Join(d.Workbooks, "; "), strings.Join(d.Operations, "; ")})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	clusters := 0
+	if len(list) > 0 {
+		clusters = list[len(list)-1].Cluster
+	}
+	fmt.Println(clusters, "clusters of likely duplicate PTIDs, see", path)
+	return nil
+}
@@ -0,0 +1,86 @@
+package excel2json
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// a workbook that has been read
+type workbook struct {
+	Path       string   `json:"path"`       // sub path of the excel file
+	Rows       int      `json:"rows"`       // number of rows read from follow_up and operative sheets
+	Unexpected []string `json:"unexpected"` // unexpected column names
+	Skipped    []rowRef `json:"skipped"`    // sheets that no events are read from, only the sheet fields are set
+}
+
+// data-quality scorecard of a workbook
+type scorecard struct {
+	Workbook          string `json:"workbook"`
+	Rows              int    `json:"rows"`
+	Events            int    `json:"events"`
+	Fixes             int    `json:"fix_events"`
+	InvalidDates      int    `json:"invalid_dates"`
+	InvalidCodes      int    `json:"invalid_codes"`
+	UnexpectedColumns int    `json:"unexpected_columns"`
+}
+
+// scorecards returns a data-quality scorecard for each workbook that has been read.
+// Events are counted once for each workbook they come from, even if they were merged with
+// a duplicate from another workbook.
+func scorecards() []scorecard {
+	events := allEvents()
+	cards := []scorecard{}
+	for _, w := range allWorkbooks {
+		c := scorecard{Workbook: w.Path, Rows: w.Rows, UnexpectedColumns: len(w.Unexpected)}
+		for _, ev := range events {
+			if !ev.Source.fromFile(w.Path) {
+				continue
+			}
+			c.Events++
+			// invalid values and dates that were kept on the event
+			for _, msg := range *ev.Fix {
+				if strings.HasPrefix(msg.Msg, "invalid value") || strings.HasPrefix(msg.Msg, "organism not in the organism table") {
+					c.InvalidCodes++
+				} else if strings.HasPrefix(msg.Msg, "invalid format") {
+					c.InvalidDates++
+				}
+			}
+		}
+		for _, f := range allFix {
+			if !f.Source.fromFile(w.Path) {
+				continue
+			}
+			c.Fixes++
+			if f.Reason == fixInvalidDate {
+				c.InvalidDates++
+			} else if f.Reason == fixInvalidCode {
+				c.InvalidCodes++
+			}
+		}
+		cards = append(cards, c)
+	}
+	return cards
+}
+
+// writeScorecards writes the data-quality scorecards of all workbooks as a table to w.
+func writeScorecards(w io.Writer) {
+	t := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(t, "WORKBOOK\tROWS\tEVENTS\tFIX EVENTS\tINVALID DATES\tINVALID CODES\tUNEXPECTED COLUMNS")
+	for _, c := range scorecards() {
+		fmt.Fprintf(t, "%s\t%d\t%d\t%d\t%d\t%d\t%d\n", c.Workbook, c.Rows, c.Events, c.Fixes,
+			c.InvalidDates, c.InvalidCodes, c.UnexpectedColumns)
+	}
+	t.Flush()
+}
+
+// Warnings returns the number of problems found in the workbooks that have been read:
+// fix events, invalid dates and codes kept on events, and unexpected columns.
+func Warnings() int {
+	n := 0
+	for _, c := range scorecards() {
+		n += c.Fixes + c.InvalidDates + c.InvalidCodes + c.UnexpectedColumns
+	}
+	return n
+}
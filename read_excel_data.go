@@ -21,1759 +21,1557 @@ func init() {
 // ReadExcelData uses the returned values from the function ExcelToSlice to
 // create different types of events, and stores in the slices.
 func ReadExcelData(e *log.Logger, path string, jsonFile *os.File, columnsChecker string) {
+	// get the sub path of the original path, relative to the root folder,
+	// stop if the file is outside the root folder
+	sub, err := helper.SubPath(path, opts.Root)
+	helper.CheckErr(e, err)
 	// slices is a slice of slices of maps, each slice of maps represents a sheet, and each map is a row in a sheet
 	// keyList is a slice of slices of strings, each slice of strings is a header row of a sheet
-	slices, keyList := ExcelToSlice(e, path, columnsChecker)
-	// get the sub path of the original path
-	path = helper.SubPath(path, "valve_registry")
+	// names is a slice of the sheet names
+	// types is a slice of the sheet types
+	// unexpected is a slice of the unexpected column names
+	slices, keyList, names, types, unexpected := ExcelToSlice(e, path, columnsChecker)
+	path = sub
+	// keep track of the workbook for the data-quality report
+	w := workbook{Path: path, Unexpected: unexpected}
 	// j is the index of sheets
 	// s is a slice of maps representing the excel sheet of index j
 	for j, s := range slices {
-		// if s equals nil, s is not a follow_up sheet
-		if s == nil {
+		// the type of the sheet selects how its rows are read
+		switch types[j] {
+		// if the type is empty, s is not a follow_up sheet
+		case "":
 			fmt.Println("oops! this is not a follow_up sheet: ", path, "sheet #:", j+1)
-		} else {
+			w.Skipped = append(w.Skipped, rowRef{Path: path, SheetName: names[j], Sheet: j + 1})
+		case "followup":
 			// s is a follow_up excel sheet
 			fmt.Println("Bingo! this is a follow_up sheet: ", path, "sheet #:", j+1)
-			// keys is the header row of the excel sheet of index j
-			keys := keyList[j]
-			// check the number of PTID and STATUS' colomns
-			// p1, p2 is the PTID column names
-			p1, p2 := helper.CheckPtidColumns(e, path, j, keys)
-
-			// st1, st2 is the status column names
-			st1, st2 := helper.CheckStatusColumns(e, path, j, keys)
-			// i is the index of rows
-			// m is the map representing the correspnding row with the index i
-			for i, m := range s {
-
-				// check if the row is empty,
-				// if not, start to read the data
-				//	if !helper.CheckEmptyRow(keys, m) {
+			// count the rows read
+			w.Rows += len(s)
+			readFollowupSheet(e, path, names[j], j, s, keyList[j])
+		case "operative":
+			// s is an operative registry sheet
+			fmt.Println("Bingo! this is an operative sheet: ", path, "sheet #:", j+1)
+			// count the rows read
+			w.Rows += len(s)
+			readOperativeSheet(e, path, names[j], j, s, keyList[j])
+		default:
+			// a sheet type that has no events to read
+			fmt.Println("no events are read from sheets of type", types[j]+":", path, "sheet #:", j+1)
+			w.Skipped = append(w.Skipped, rowRef{Path: path, SheetName: names[j], Sheet: j + 1})
+		}
+	}
+	// store the workbook
+	allWorkbooks = append(allWorkbooks, w)
+}
 
-				// check PTID
-				ID1, ID2 := m[p1], m[p2]
-				// assign PTIDs
-				diffID := helper.AssignPTID(&ID1, &ID2)
-
-				// Check STATUS
-				S1, S2 := m[st1], m[st2]
-				// assign status
-				diffStatus := helper.AssignStatus(&S1, &S2)
-
-				// get the date of surgery
-				operDate, operEst := helper.CheckOperationDate(e, path, j, i, keys, m)
-
-				// check if format of PTID is LLLFDDMMYY
-				helper.CheckPtidFormat(ID1, operDate, e, path, j, i)
-
-				// followup event
-				var coag, plat int
-				var poNYHA float64
-				var unusual, notes string
-				// assign values to unusual and notes
-				unusual = m["STATUS=O REASON"]
-				notes = strings.TrimSpace(m["FU NOTES"] + " " + m["NOTES"])
-				// validate int and float values
-				coagValid := helper.CheckIntValue(&coag, m["COAG"], nums[:3])
-				nyhaValid := helper.CheckFloatValue(&poNYHA, m["PO_NYHA"], floats[1:])
-				platValid := helper.CheckIntValue(&plat, m["PLAT"], nums[:3])
-				// create followup notes
-				fuNotes := helper.FollowupNotes(S1, m["FU NOTES"], m["NOTES"], m["STATUS=O REASON"], plat, coag, poNYHA)
-
-				// check FU_D format
-				date, est := helper.CheckDateFormat(e, path, j, i, "follow_up Date", m["FU_D"])
-				// est equals 0 or 1 means that the date format was parsed to YYYY-MM-DD
-				if est == 0 || est == 1 {
-					// create followup event
-					fu := followups{
-						PTID:    ID1,
-						Date:    date,
-						Type:    "followup",
-						Status:  &S1,
-						Plat:    plat,
-						PoNYHA:  poNYHA,
-						Coag:    coag,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-
-					// source: add path
-					fu.Source.Path = append(fu.Source.Path, path)
-					// add Notes
-					if !(m["NOTES"] == "" && m["FU NOTES"] == "") {
-						fu.Notes = &notes
-					}
-					// add Unusual
-					if unusual != "" {
-						fu.Unusual = &unusual
-					}
-					// check PTID
-					if diffID {
-						msg := errMessage{"patient_id", "two different PTIDs: '" + ID1 + "', '" + ID2 + "' "}
-						fu.Fix = append(fu.Fix, msg)
-					}
-					// check STATUS
-					// if true means both statuses are non-empty and not equal
-					if diffStatus {
-						msg := errMessage{"status", "two different Statuses: '" + S1 + "', '" + S2 + "'"}
-						fu.Fix = append(fu.Fix, msg)
-						// if one of the codes is D, L, N, or O and the other code is A or R, put the D, L, N or O
-						if helper.StringInSlice(0, S1, codes[4:6]) && helper.StringInSlice(0, S2, codes[:4]) {
-							fu.Status = &S2
-						}
-					}
+// readFollowupSheet creates the events of the rows s of a follow_up sheet, and stores them in the slices.
+// path is the sub path of the excel file, j is the index of the sheet and keys is its header row.
+func readFollowupSheet(e *log.Logger, path string, sheetName string, j int, s []map[string]string, keys []string) {
+	// check the number of PTID and STATUS' colomns
+	// p1, p2 is the PTID column names
+	p1, p2 := helper.CheckPtidColumns(e, path, j, keys)
+
+	// the status column names, any number of them
+	statusCols := helper.StatusColumns(keys)
+	// the discharge date columns of the index operation, if the sheet has them
+	dischargeCols := classColumns("followup", "discharge", operativeColumns, keys)
+	// i is the index of rows
+	// m is the map representing the correspnding row with the index i
+	for i, m := range s {
+
+		// check if the row is empty,
+		// if not, start to read the data
+		//	if !helper.CheckEmptyRow(keys, m) {
+
+		// check PTID
+		ID1, ID2 := m[p1], m[p2]
+		// replace the confirmed aliases by their PTIDs
+		ID1, ID2 = resolveAlias(ID1), resolveAlias(ID2)
+		// assign PTIDs
+		diffID := helper.AssignPTID(&ID1, &ID2)
+
+		// reconcile the STATUS columns to one status
+		status := reconcileStatus(m, statusCols)
+		S1 := status.Status
+		if status.Note != "" {
+			e.Println(path, "Sheet #:", j+1, "Row #:", i+2, "INFO:", status.Note)
+		}
 
-					// validate status' values
-					if *fu.Status == "" {
-						fu.Status = nil
-					} else if !helper.StringInSlice(0, S1, codes) {
-						msg := errMessage{"code", "invalid value: '" + S1 + "'"}
-						fu.Fix = append(fu.Fix, msg)
-					}
+		// r is the row that the events of this row come from,
+		// it keeps the PTID and STATUS cells
+		r := newRowRef(path, sheetName, j, i, m, append([]string{p1, p2}, statusCols...)...)
 
-					if !nyhaValid {
-						msg := errMessage{"post_op_nyha", "invalid value: '" + m["PO_NYHA"] + "'"}
-						fu.Fix = append(fu.Fix, msg)
-						//errlog.ErrorLog(e, path, j, fU.PTID, i, fU.Type, "PO_NYHA", m["PO_NYHA"])
-					}
-					if !coagValid {
-						msg := errMessage{"anti_coagulants", "invalid value: '" + m["COAG"] + "'"}
-						fu.Fix = append(fu.Fix, msg)
-						//errlog.ErrorLog(e, path, j, fU.PTID, i, fU.Type, "COAG", m["COAG"])
-					}
-					if !platValid {
-						msg := errMessage{"anti_platelet", "invalid value: '" + m["PLAT"] + "'"}
-						fu.Fix = append(fu.Fix, msg)
-						//	errlog.ErrorLog(e, path, j, fU.PTID, i, fU.Type, "PLAT", m["PLAT"])
-					}
-					// if no duplicates, store in a slice
-					if !fu.CompareFollowups(allFollowUps) {
-						allFollowUps = append(allFollowUps, fu)
-					}
+		// get the date of surgery
+		operDate, operEst := helper.CheckOperationDate(e, path, j, i, keys, m)
 
-					// est == 3 means that date has invalid format,
-					// then create a fix event
-				} else if est == 3 {
+		// check if format of PTID is LLLFDDMMYY
+		helper.CheckPtidFormat(ID1, operDate, e, path, j, i)
+		// check the row against the rules of the rules file, a row without PTID has no events
+		if ID1 != "" {
+			checkRules(e, path, "followup", j, i, keys, m, ID1, r)
+		}
+		// two PTIDs or STATUS columns that disagree, create fix events
+		if diffID {
+			f := newFix(ID1, "row", fixConflictingSources, r, m, p1, p2)
+			f.Msg = "two different PTIDs: '" + ID1 + "', '" + ID2 + "'"
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
+		if status.Conflict && ID1 != "" {
+			f := newFix(ID1, "followup", fixConflictingSources, r, m, statusCols...)
+			f.Msg = status.conflictMsg().Msg
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path
-					f.Source.Path = append(f.Source.Path, path)
-					// add msg
-					f.Msg = "followup event with invalid date: '" + date +
-						"', here is the follow up info: " + fuNotes
+		// the index operation of the row, an operation event if asked so that re-operations have
+		// a parent, else it only times the events of the patient
+		if (operEst == 0 || operEst == 1) && ID1 != "" {
+			op := operation{
+				PTID:    ID1,
+				Type:    "operation",
+				Date:    operDate,
+				DateEst: operEst,
+				Source:  newSource(r, m, append([]string{helper.OperationDateColumn(keys)}, dischargeCols...)...)}
+			if v := firstValue(m, dischargeCols); v != "" {
+				if d, dEst := helper.CheckDateFormat(e, path, j, i, "DISCHARGE", v); dEst == 0 || dEst == 1 {
+					op.Discharge = &d
+				}
+			}
+			// if no duplicates, store in a slice
+			if !opts.IndexOps {
+				if !op.CompareOperation(allIndexOps) {
+					allIndexOps = append(allIndexOps, op)
+				}
+			} else if !op.CompareOperation(allOperation) {
+				allOperation = append(allOperation, op)
+			}
+		}
 
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
+		// followup event
+		var coag, plat int
+		var poNYHA float64
+		var unusual, notes string
+		// assign values to unusual and notes
+		unusual = m["STATUS=O REASON"]
+		notes = strings.TrimSpace(m["FU NOTES"] + " " + m["NOTES"])
+		// validate int and float values
+		coagValid := helper.CheckIntValue(&coag, m["COAG"], nums[:3])
+		nyhaValid := helper.CheckFloatValue(&poNYHA, m["PO_NYHA"], floats[1:])
+		platValid := helper.CheckIntValue(&plat, m["PLAT"], nums[:3])
+		// create followup notes
+		fuNotes := helper.FollowupNotes(S1, m["FU NOTES"], m["NOTES"], m["STATUS=O REASON"], plat, coag, poNYHA)
+
+		// check FU_D format
+		date, est := helper.CheckDateFormat(e, path, j, i, "follow_up Date", m["FU_D"])
+		// est equals 0 or 1 means that the date format was parsed to YYYY-MM-DD
+		if est == 0 || est == 1 {
+			// create followup event
+			fu := followups{
+				PTID:    ID1,
+				Date:    date,
+				Type:    "followup",
+				Status:  &S1,
+				Plat:    plat,
+				PoNYHA:  poNYHA,
+				Coag:    coag,
+				DateEst: est,
+				Source:  newSource(r, m, "FU_D", "COAG", "PLAT", "PO_NYHA", "NOTES", "FU NOTES", "STATUS=O REASON")}
+
+			// add Notes
+			if !(m["NOTES"] == "" && m["FU NOTES"] == "") {
+				fu.Notes = &notes
+			}
+			// add Unusual
+			if unusual != "" {
+				fu.Unusual = &unusual
+			}
+			// check PTID
+			if diffID {
+				msg := errMessage{"patient_id", "two different PTIDs: '" + ID1 + "', '" + ID2 + "' "}
+				fu.Fix = append(fu.Fix, msg)
+			}
+			// check STATUS
+			// the statuses are non-empty and not equal, the one of the highest precedence is kept
+			if status.Conflict {
+				fu.Fix = append(fu.Fix, status.conflictMsg())
+			}
+			if status.Note != "" {
+				fu.Audit = &status.Note
+			}
 
-					// est equal 2: follow up date is empty
-				} else if est == 2 {
-					// estimate last_known_alive date
-					lkaDate, lkaEst := helper.CheckDateFormat(e, path, j, i, "LKA_Date", m["LKA_D"])
+			// validate status' values
+			if *fu.Status == "" {
+				fu.Status = nil
+			}
+			fu.Fix = append(fu.Fix, status.invalidMsgs()...)
 
-					// if last_known_alive date is valid,
-					// create a last_known_alive event
-					if lkaEst == 0 || lkaEst == 1 {
+			if !nyhaValid {
+				msg := errMessage{"post_op_nyha", "invalid value: '" + m["PO_NYHA"] + "'"}
+				fu.Fix = append(fu.Fix, msg)
+				//errlog.ErrorLog(e, path, j, fU.PTID, i, fU.Type, "PO_NYHA", m["PO_NYHA"])
+			}
+			if !coagValid {
+				msg := errMessage{"anti_coagulants", "invalid value: '" + m["COAG"] + "'"}
+				fu.Fix = append(fu.Fix, msg)
+				//errlog.ErrorLog(e, path, j, fU.PTID, i, fU.Type, "COAG", m["COAG"])
+			}
+			if !platValid {
+				msg := errMessage{"anti_platelet", "invalid value: '" + m["PLAT"] + "'"}
+				fu.Fix = append(fu.Fix, msg)
+				//	errlog.ErrorLog(e, path, j, fU.PTID, i, fU.Type, "PLAT", m["PLAT"])
+			}
+			// if no duplicates, store in a slice
+			if !fu.CompareFollowups(allFollowUps) {
+				allFollowUps = append(allFollowUps, fu)
+			}
 
-						lka := followups{
-							PTID:    ID1,
-							Type:    "last_known_alive",
-							Date:    lkaDate,
-							Coag:    coag,
-							PoNYHA:  poNYHA,
-							Plat:    plat,
-							DateEst: lkaEst,
-							Source:  source{Type: "followup", Path: []string{}}}
-
-						// source: add path
-						lka.Source.Path = append(lka.Source.Path, path)
-
-						// add notes if exists
-						if !(m["NOTES"] == "" && m["FU NOTES"] == "") {
-							lka.Notes = &notes
-						}
-						// add Unusual
-						if unusual != "" {
-							lka.Unusual = &unusual
-						}
-						// check PTID
-						if diffID {
-							msg := errMessage{"patient_id", "two different PTIDs: '" + ID1 + "', '" + ID2 + "' "}
-							lka.Fix = append(lka.Fix, msg)
-						}
-						// check status
-						// if true means both statuses are non-empty and not equal
-						if diffStatus {
-							msg := errMessage{"status", "two different Statuses: '" + S1 + "', '" + S2 + "'"}
-							lka.Fix = append(lka.Fix, msg)
-						}
-						// validate PO_NYHA
-						if !nyhaValid {
-							msg := errMessage{"post_op_nyha", "invalid value: '" + m["PO_NYHA"] + "'"}
-							lka.Fix = append(lka.Fix, msg)
-						}
-						// validate COAG
-						if !coagValid {
-							msg := errMessage{"anti_coagulants", "invalid value: '" + m["COAG"] + "'"}
-							lka.Fix = append(lka.Fix, msg)
-						}
+			// est == 3 means that date has invalid format,
+			// then create a fix event
+		} else if est == 3 {
 
-						// validate PLAT
-						if !platValid {
-							msg := errMessage{"anti_platelet", "invalid value: '" + m["PLAT"] + "'"}
-							lka.Fix = append(lka.Fix, msg)
-						}
+			f := newFix(ID1, "followup", fixInvalidDate, r, m, "FU_D", "COAG", "PLAT", "PO_NYHA", "NOTES", "FU NOTES", "STATUS=O REASON")
+			// add msg
+			f.Msg = "followup event with invalid date: '" + date +
+				"', here is the follow up info: " + fuNotes
 
-						// if no duplicates, store in a slice
-						if !lka.CompareFollowups(allLKA) {
-							allLKA = append(allLKA, lka)
-						}
-						// if last_known_alive date has invalid date format,
-						// create a fix event
-					} else if lkaEst == 3 {
-						f := general{
-							PTID:    ID1,
-							Type:    "fix",
-							Date:    "1900-01-01",
-							DateEst: 1,
-							Source:  source{Type: "followup", Path: []string{}}}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+
+			// est equal 2: follow up date is empty
+		} else if est == 2 {
+			// estimate last_known_alive date
+			lkaDate, lkaEst := helper.CheckDateFormat(e, path, j, i, "LKA_Date", m["LKA_D"])
+
+			// if last_known_alive date is valid,
+			// create a last_known_alive event
+			if lkaEst == 0 || lkaEst == 1 {
+
+				lka := followups{
+					PTID:    ID1,
+					Type:    "last_known_alive",
+					Date:    lkaDate,
+					Coag:    coag,
+					PoNYHA:  poNYHA,
+					Plat:    plat,
+					DateEst: lkaEst,
+					Source:  newSource(r, m, "FU_D", "LKA_D", "COAG", "PLAT", "PO_NYHA", "NOTES", "FU NOTES", "STATUS=O REASON")}
+
+				// add notes if exists
+				if !(m["NOTES"] == "" && m["FU NOTES"] == "") {
+					lka.Notes = &notes
+				}
+				// add Unusual
+				if unusual != "" {
+					lka.Unusual = &unusual
+				}
+				// check PTID
+				if diffID {
+					msg := errMessage{"patient_id", "two different PTIDs: '" + ID1 + "', '" + ID2 + "' "}
+					lka.Fix = append(lka.Fix, msg)
+				}
+				// check status
+				// the statuses are non-empty and not equal
+				if status.Conflict {
+					lka.Fix = append(lka.Fix, status.conflictMsg())
+				}
+				// validate PO_NYHA
+				if !nyhaValid {
+					msg := errMessage{"post_op_nyha", "invalid value: '" + m["PO_NYHA"] + "'"}
+					lka.Fix = append(lka.Fix, msg)
+				}
+				// validate COAG
+				if !coagValid {
+					msg := errMessage{"anti_coagulants", "invalid value: '" + m["COAG"] + "'"}
+					lka.Fix = append(lka.Fix, msg)
+				}
 
-						// LKA date with invalid format
-						f.Msg = "last_known_alive date with invalid format: '" + lkaDate +
-							"' , and FU NOTES without date associated. Here are the followup notes: " + fuNotes
+				// validate PLAT
+				if !platValid {
+					msg := errMessage{"anti_platelet", "invalid value: '" + m["PLAT"] + "'"}
+					lka.Fix = append(lka.Fix, msg)
+				}
 
-						// Source: add path
-						f.Source.Path = append(f.Source.Path, path)
+				// if no duplicates, store in a slice
+				if !lka.CompareFollowups(allLKA) {
+					allLKA = append(allLKA, lka)
+				}
+				// if last_known_alive date has invalid date format,
+				// create a fix event
+			} else if lkaEst == 3 {
+				f := newFix(ID1, "last_known_alive", fixInvalidDate, r, m, "LKA_D", "FU_D", "COAG", "PLAT", "PO_NYHA", "NOTES", "FU NOTES", "STATUS=O REASON")
+
+				// LKA date with invalid format
+				f.Msg = "last_known_alive date with invalid format: '" + lkaDate +
+					"' , and FU NOTES without date associated. Here are the followup notes: " + fuNotes
+
+				// if no duplicates, store in a slice
+				if !f.CompareFix(allFix) {
+					allFix = append(allFix, f)
+				}
+				// else if last_known_alive date is also empty, but at least one of the followup fields is not empty,
+				// create a fix event
+			} else if lkaEst == 2 && (m["FU NOTES"] != "" || (coag != -9 && coag != 0) || (plat != -9 && plat != 0) ||
+				(poNYHA != -9 && poNYHA != 0) || m["STATUS=O REASON"] != "" || m["NOTES"] != "") {
+				f := newFix(ID1, "followup", fixMissingDate, r, m, "FU_D", "LKA_D", "COAG", "PLAT", "PO_NYHA", "NOTES", "FU NOTES", "STATUS=O REASON")
+
+				// LKA date is missing
+				f.Msg = "followup and last_known_alive events without date associated, here are the followup notes: " + fuNotes
+
+				// if no duplicates, store in a slice
+				if !f.CompareFix(allFix) {
+					allFix = append(allFix, f)
+				}
+			}
+		}
 
-						// if no duplicates, store in a slice
-						if !f.CompareEvents(allFix) {
-							allFix = append(allFix, f)
-						}
-						// else if last_known_alive date is also empty, but at least one of the followup fields is not empty,
-						// create a fix event
-					} else if lkaEst == 2 && (m["FU NOTES"] != "" || (coag != -9 && coag != 0) || (plat != -9 && plat != 0) ||
-						(poNYHA != -9 && poNYHA != 0) || m["STATUS=O REASON"] != "" || m["NOTES"] != "") {
-						f := general{
-							PTID:    ID1,
-							Type:    "fix",
-							Date:    "1900-01-01",
-							DateEst: 1,
-							Source:  source{Type: "followup", Path: []string{}}}
+		// last_known_alive event
+		// estimate the value of "LKA_D"
+		lkaDate, lkaEst := helper.CheckDateFormat(e, path, j, i, "LKA_Date", m["LKA_D"])
+
+		if lkaEst == 0 || lkaEst == 1 {
+			// if "LKA_D" and "FU_D" both have valid values,
+			// create a last_known_alive event and set all -9s in the event
+			if m["FU_D"] != "" {
+
+				lka := followups{
+					PTID:    ID1,
+					Type:    "last_known_alive",
+					Date:    lkaDate,
+					Coag:    -9,
+					PoNYHA:  -9,
+					Plat:    -9,
+					DateEst: lkaEst,
+					Source:  newSource(r, m, "LKA_D", "FU_D")}
+
+				// if no duplicates, store in a slice
+				if !lka.CompareFollowups(allLKA) {
+					allLKA = append(allLKA, lka)
+				}
+			}
 
-						// LKA date is missing
-						f.Msg = "followup and last_known_alive events without date associated, here are the followup notes: " + fuNotes
+		} else if lkaEst == 3 {
+			// else if "LKA_D" has invalid date and FU_D exists,
+			// then create a fix event
+			if m["FU_D"] != "" {
 
-						// Source: add path
-						f.Source.Path = append(f.Source.Path, path)
-						// if no duplicates, store in a slice
-						if !f.CompareEvents(allFix) {
-							allFix = append(allFix, f)
-						}
-					}
+				f := newFix(ID1, "last_known_alive", fixInvalidDate, r, m, "LKA_D", "FU_D")
+				f.Msg = "LKA Date with invalid format: '" + lkaDate + "'"
+
+				// if no duplicates, store in a slice
+				if !f.CompareFix(allFix) {
+					allFix = append(allFix, f)
 				}
+			}
+		}
 
-				// last_known_alive event
-				// estimate the value of "LKA_D"
-				lkaDate, lkaEst := helper.CheckDateFormat(e, path, j, i, "LKA_Date", m["LKA_D"])
+		// lost_to_followup event
+
+		// create the “lost_to_followup” event if the reconciled status is “L”, by the default precedence
+		// a STATUS column that is “D” or “N” is kept over an “L”.
+		// Date will be (in order of preference) either the “STATUS=L DATE” field, or the STATUSDATE or the FU_D if LKA_D not exists.
+		// If none of those dates are available, create a fix event instead.
+		if S1 == "L" {
+			// estimate the value of "Status=L Date"
+			date, est = helper.CheckDateFormat(e, path, j, i, "Status=L Date", m["STATUS=L DATE"])
+			// create notes string
+			notes := strings.TrimSpace(m["FU NOTES"] + " " + m["NOTES"] + " " + m["STATUS=O REASON"])
+			// if "Status=L Date" has valid value, create a lost_to_followup event,
+			// and set "Status=L Date" as the date
+			if est == 0 || est == 1 {
+				lost := lostFollowup{
+					PTID:    ID1,
+					Type:    "lost_to_followup",
+					Date:    date,
+					DateEst: est,
+					LkaDate: &lkaDate,
+					Source:  newSource(r, m, "STATUS=L DATE", "LKA_D", "NOTES", "FU NOTES", "STATUS=O REASON")}
+
+				// check LKA_Date
+				// LKA_Date is empty, set null in json
+				if lkaEst == 2 {
+					lost.LkaDate = nil
+					// invalid format, add fix message
+				} else if lkaEst == 3 {
+					lost.LkaDate = nil
+					msg := errMessage{"lka_date", "invalid format of last_known_alive date: '" + lkaDate +
+						"', cannot compare with the lost_to_followup date."}
+					lost.Fix = append(lost.Fix, msg)
+				} else if helper.DateLaterThan(lkaDate, date) {
+					msg := errMessage{"lka_date", "conflict of 'L' status before 'LKA date' - has patient been recovered?"}
+					lost.Fix = append(lost.Fix, msg)
+				}
 
-				if lkaEst == 0 || lkaEst == 1 {
-					// if "LKA_D" and "FU_D" both have valid values,
-					// create a last_known_alive event and set all -9s in the event
-					if m["FU_D"] != "" {
+				// add notes
+				if !(m["FU NOTES"] == "" && m["NOTES"] == "" && m["STATUS=O REASON"] == "") {
+					lost.Notes = &notes
+				}
 
-						lka := followups{
-							PTID:    ID1,
-							Type:    "last_known_alive",
-							Date:    lkaDate,
-							Coag:    -9,
-							PoNYHA:  -9,
-							Plat:    -9,
-							DateEst: lkaEst,
-							Source:  source{Type: "followup", Path: []string{}}}
+				// if no duplicates, store in a slice
+				if !lost.CompareLostFollowup(allLostFollowups) {
+					allLostFollowups = append(allLostFollowups, lost)
+				}
 
-						// source: add path
-						lka.Source.Path = append(lka.Source.Path, path)
+				// if “STATUS=L DATE” field is empty
+			} else if est == 2 {
+				// get the ".*STATUSDATE"
+				var statusDate string
+				var statusEst int
+				var statusCol string
+
+				for _, k := range keys {
+					matched, _ := regexp.MatchString("^.*STATUSDATE$", k)
+					if matched {
+						statusDate, statusEst = helper.CheckDateFormat(e, path, j, i, "Status Date", m[k])
+						statusCol = k
+						break
+					} else {
+						statusDate, statusEst = "", 2
+					}
+				}
+				// estimate the value of "FU_D"
+				fuDate, fuEst := helper.CheckDateFormat(e, path, j, i, "follow_up Date", m["FU_D"])
 
-						// if no duplicates, store in a slice
-						if !lka.CompareFollowups(allLKA) {
-							allLKA = append(allLKA, lka)
-						}
+				// if STATUSDATE is valid, create a lost_to_followup event,
+				// and set the STATUSDATE as the date value
+				if statusEst == 0 || statusEst == 1 {
+					lost := lostFollowup{
+						PTID:    ID1,
+						Type:    "lost_to_followup",
+						Date:    statusDate,
+						DateEst: statusEst,
+						LkaDate: &lkaDate,
+						Source:  newSource(r, m, "STATUS=L DATE", statusCol, "LKA_D", "NOTES", "FU NOTES", "STATUS=O REASON")}
+
+					// check LKA_Date
+					// LKA_Date is empty, set null in json
+					if lkaEst == 2 {
+						lost.LkaDate = nil
+
+						// invalid format, add fix message
+					} else if lkaEst == 3 {
+						lost.LkaDate = nil
+						msg := errMessage{"lka_date", "invalid format of last_known_alive date: '" + lkaDate +
+							"', cannot compare with the lost_to_followup date."}
+						lost.Fix = append(lost.Fix, msg)
+					} else if helper.DateLaterThan(lkaDate, statusDate) {
+						msg := errMessage{"lka_date", "conflict of 'L' status before 'LKA date' - has patient been recovered?"}
+						lost.Fix = append(lost.Fix, msg)
 					}
 
-				} else if lkaEst == 3 {
-					// else if "LKA_D" has invalid date and FU_D exists,
-					// then create a fix event
-					if m["FU_D"] != "" {
+					// add Notes
+					if !(m["FU NOTES"] == "" && m["NOTES"] == "" && m["STATUS=O REASON"] == "") {
+						lost.Notes = &notes
+					}
 
-						f := general{
-							PTID:    ID1,
-							Type:    "fix",
-							Date:    "1900-01-01",
-							DateEst: 1,
-							Msg:     "LKA Date with invalid format: '" + lkaDate + "'",
-							Source:  source{Type: "followup", Path: []string{}}}
+					// if no duplicates, store in a slice
+					if !lost.CompareLostFollowup(allLostFollowups) {
+						allLostFollowups = append(allLostFollowups, lost)
+					}
+					// else if STATUSDATE has invalid format, create a fix event
+				} else if statusEst == 3 {
 
-						// Source: add path
-						f.Source.Path = append(f.Source.Path, path)
+					f := newFix(ID1, "lost_to_followup", fixInvalidDate, r, m, statusCol, "STATUS=L DATE", "LKA_D", "NOTES", "FU NOTES", "STATUS=O REASON")
+					f.Msg = "Invalid STATUSDATE: '" + statusDate + "', Notes: '" + notes + "'"
 
-						// if no duplicates, store in a slice
-						if !f.CompareEvents(allFix) {
-							allFix = append(allFix, f)
-						}
+					// add lka_date
+					if lkaEst != 2 {
+						f.Msg += ", lka_date: '" + lkaDate + "'"
 					}
-				}
+					// if no duplicates, store in a slice
+					if !f.CompareFix(allFix) {
+						allFix = append(allFix, f)
+					}
+					// else if STATUSDATE is empty, then consider the value of followup date: FU_D
+				} else if statusEst == 2 {
+
+					if fuEst == 0 || fuEst == 1 {
+						// if FU_D is valid, create a lost_to_followup event,
+						// and set the FU_D as the date value
 
-				// lost_to_followup event
-
-				// if one of the STATUS columns is “L” and the other is “D” or “N”, do not create the “lost_to_followup”,
-				// otherwise, create the “lost_to_followup” event if one is “L”.
-				// Date will be (in order of preference) either the “STATUS=L DATE” field, or the STATUSDATE or the FU_D if LKA_D not exists.
-				// If none of those dates are available, put 1900-02-02 as the date.
-				if S1 == "L" && !helper.StringInSlice(0, S2, codes[:2]) || (S2 == "L" && !helper.StringInSlice(0, S1, codes[:2])) {
-					// estimate the value of "Status=L Date"
-					date, est = helper.CheckDateFormat(e, path, j, i, "Status=L Date", m["STATUS=L DATE"])
-					// create notes string
-					notes := strings.TrimSpace(m["FU NOTES"] + " " + m["NOTES"] + " " + m["STATUS=O REASON"])
-					// if "Status=L Date" has valid value, create a lost_to_followup event,
-					// and set "Status=L Date" as the date
-					if est == 0 || est == 1 {
 						lost := lostFollowup{
 							PTID:    ID1,
 							Type:    "lost_to_followup",
-							Date:    date,
-							DateEst: est,
+							Date:    fuDate,
+							DateEst: fuEst,
 							LkaDate: &lkaDate,
-							Source:  source{Type: "followup", Path: []string{}}}
+							Source:  newSource(r, m, "STATUS=L DATE", statusCol, "FU_D", "LKA_D", "NOTES", "FU NOTES", "STATUS=O REASON")}
 
 						// check LKA_Date
 						// LKA_Date is empty, set null in json
 						if lkaEst == 2 {
 							lost.LkaDate = nil
+
 							// invalid format, add fix message
 						} else if lkaEst == 3 {
 							lost.LkaDate = nil
 							msg := errMessage{"lka_date", "invalid format of last_known_alive date: '" + lkaDate +
 								"', cannot compare with the lost_to_followup date."}
 							lost.Fix = append(lost.Fix, msg)
-						} else if helper.DateLaterThan(lkaDate, date) {
+						} else if helper.DateLaterThan(lkaDate, fuDate) {
 							msg := errMessage{"lka_date", "conflict of 'L' status before 'LKA date' - has patient been recovered?"}
 							lost.Fix = append(lost.Fix, msg)
 						}
 
-						// add notes
+						// add Notes
 						if !(m["FU NOTES"] == "" && m["NOTES"] == "" && m["STATUS=O REASON"] == "") {
 							lost.Notes = &notes
 						}
-						// Source: add path
-						lost.Source.Path = append(lost.Source.Path, path)
 
 						// if no duplicates, store in a slice
 						if !lost.CompareLostFollowup(allLostFollowups) {
 							allLostFollowups = append(allLostFollowups, lost)
 						}
 
-						// if “STATUS=L DATE” field is empty
-					} else if est == 2 {
-						// get the ".*STATUSDATE"
-						var statusDate string
-						var statusEst int
-
-						for _, k := range keys {
-							matched, _ := regexp.MatchString("^.*STATUSDATE$", k)
-							if matched {
-								statusDate, statusEst = helper.CheckDateFormat(e, path, j, i, "Status Date", m[k])
-								break
-							} else {
-								statusDate, statusEst = "", 2
-							}
-						}
-						// estimate the value of "FU_D"
-						fuDate, fuEst := helper.CheckDateFormat(e, path, j, i, "follow_up Date", m["FU_D"])
-
-						// if STATUSDATE is valid, create a lost_to_followup event,
-						// and set the STATUSDATE as the date value
-						if statusEst == 0 || statusEst == 1 {
-							lost := lostFollowup{
-								PTID:    ID1,
-								Type:    "lost_to_followup",
-								Date:    statusDate,
-								DateEst: statusEst,
-								LkaDate: &lkaDate,
-								Source:  source{Type: "followup", Path: []string{}}}
-
-							// check LKA_Date
-							// LKA_Date is empty, set null in json
-							if lkaEst == 2 {
-								lost.LkaDate = nil
-
-								// invalid format, add fix message
-							} else if lkaEst == 3 {
-								lost.LkaDate = nil
-								msg := errMessage{"lka_date", "invalid format of last_known_alive date: '" + lkaDate +
-									"', cannot compare with the lost_to_followup date."}
-								lost.Fix = append(lost.Fix, msg)
-							} else if helper.DateLaterThan(lkaDate, statusDate) {
-								msg := errMessage{"lka_date", "conflict of 'L' status before 'LKA date' - has patient been recovered?"}
-								lost.Fix = append(lost.Fix, msg)
-							}
-
-							// add Notes
-							if !(m["FU NOTES"] == "" && m["NOTES"] == "" && m["STATUS=O REASON"] == "") {
-								lost.Notes = &notes
-							}
-							// Source: add path
-							lost.Source.Path = append(lost.Source.Path, path)
-
-							// if no duplicates, store in a slice
-							if !lost.CompareLostFollowup(allLostFollowups) {
-								allLostFollowups = append(allLostFollowups, lost)
-							}
-							// else if STATUSDATE has invalid format, create a fix event with date "1900-02-02"
-						} else if statusEst == 3 {
-
-							f := general{
-								PTID:    ID1,
-								Type:    "fix",
-								Date:    "1900-02-02",
-								DateEst: 1,
-								Msg:     "Invalid STATUSDATE: '" + statusDate + "', Notes: '" + notes + "'",
-								Source:  source{Type: "followup", Path: []string{}}}
-
-							// add lka_date
-							if lkaEst != 2 {
-								f.Msg += ", lka_date: '" + lkaDate + "'"
-							}
-							// add path
-							f.Source.Path = append(f.Source.Path, path)
-							// if no duplicates, store in a slice
-							if !f.CompareEvents(allFix) {
-								allFix = append(allFix, f)
-							}
-							// else if STATUSDATE is empty, then consider the value of followup date: FU_D
-						} else if statusEst == 2 {
-
-							if fuEst == 0 || fuEst == 1 {
-								// if FU_D is valid, create a lost_to_followup event,
-								// and set the FU_D as the date value
-
-								lost := lostFollowup{
-									PTID:    ID1,
-									Type:    "lost_to_followup",
-									Date:    fuDate,
-									DateEst: fuEst,
-									LkaDate: &lkaDate,
-									Source:  source{Type: "followup", Path: []string{}}}
-
-								// check LKA_Date
-								// LKA_Date is empty, set null in json
-								if lkaEst == 2 {
-									lost.LkaDate = nil
-
-									// invalid format, add fix message
-								} else if lkaEst == 3 {
-									lost.LkaDate = nil
-									msg := errMessage{"lka_date", "invalid format of last_known_alive date: '" + lkaDate +
-										"', cannot compare with the lost_to_followup date."}
-									lost.Fix = append(lost.Fix, msg)
-								} else if helper.DateLaterThan(lkaDate, fuDate) {
-									msg := errMessage{"lka_date", "conflict of 'L' status before 'LKA date' - has patient been recovered?"}
-									lost.Fix = append(lost.Fix, msg)
-								}
-
-								// add Notes
-								if !(m["FU NOTES"] == "" && m["NOTES"] == "" && m["STATUS=O REASON"] == "") {
-									lost.Notes = &notes
-								}
-								// Source: add path
-								lost.Source.Path = append(lost.Source.Path, path)
-
-								// if no duplicates, store in a slice
-								if !lost.CompareLostFollowup(allLostFollowups) {
-									allLostFollowups = append(allLostFollowups, lost)
-								}
-
-								// if FU_D has invalid date format, create a fix event
-							} else if fuEst == 3 {
-
-								f := general{
-									PTID:    ID1,
-									Type:    "fix",
-									Date:    "1900-02-02",
-									DateEst: 1,
-									Msg:     "Invalid followup date: '" + fuDate + "', Notes: '" + notes + "'",
-									Source:  source{Type: "followup", Path: []string{}}}
-
-								// add lka_date
-								if lkaEst != 2 {
-									f.Msg += ", lka_date: '" + lkaDate + "'"
-								}
-
-								// add path
-								f.Source.Path = append(f.Source.Path, path)
-								// if no duplicates, store in a slice
-								if !f.CompareEvents(allFix) {
-									allFix = append(allFix, f)
-								}
-								// else if FU_D is missing, then create a lost_to_followup event,
-								// and set the date as "1900-02-02"
-							} else if fuEst == 2 {
-
-								// create a fix event
-								f := general{
-									PTID:    ID1,
-									Type:    "fix",
-									Date:    "1900-02-02",
-									DateEst: 1,
-									Msg:     "The status was L but there was no date to associate with it. Notes: '" + notes + "'",
-									Source:  source{Type: "followup", Path: []string{}}}
-
-								if lkaEst != 2 {
-									f.Msg += ", lka_date: '" + lkaDate + "'"
-								}
-								// add path
-								f.Source.Path = append(f.Source.Path, path)
-								// if no duplicates, store in a slice
-								if !f.CompareEvents(allFix) {
-									allFix = append(allFix, f)
-								}
-							}
-						}
-						// if "STATUS=L DATE" has invalid date format, create a fix event
-					} else {
-						// create a fix event
-						f := general{
-							PTID:    ID1,
-							Type:    "fix",
-							Date:    "1900-02-02",
-							DateEst: 1,
-							Msg:     "Invalid STATUS=L DATE: '" + date + "', Notes: '" + notes + "'",
-							Source:  source{Type: "followup", Path: []string{}}}
+						// if FU_D has invalid date format, create a fix event
+					} else if fuEst == 3 {
+
+						f := newFix(ID1, "lost_to_followup", fixInvalidDate, r, m, "FU_D", "STATUS=L DATE", statusCol, "LKA_D", "NOTES", "FU NOTES", "STATUS=O REASON")
+						f.Msg = "Invalid followup date: '" + fuDate + "', Notes: '" + notes + "'"
 
 						// add lka_date
 						if lkaEst != 2 {
 							f.Msg += ", lka_date: '" + lkaDate + "'"
 						}
 
-						// add path
-						f.Source.Path = append(f.Source.Path, path)
 						// if no duplicates, store in a slice
-						if !f.CompareEvents(allFix) {
+						if !f.CompareFix(allFix) {
 							allFix = append(allFix, f)
 						}
-					}
-				}
-
-				// Event Death
+						// else if FU_D is missing, then create a fix event
+						// for the lost_to_followup event
+					} else if fuEst == 2 {
 
-				// estimate death date
-				date, est = helper.CheckDateFormat(e, path, j, i, "DTH_Date", m["DTH_D"])
-
-				// assign operative
-				var operative string
-				if m["SURVIVAL"] == "0" {
-					operative = "1"
-				} else {
-					operative = "0"
-				}
-				// death date with valid format
-				if est == 0 || est == 1 {
-					d := death{
-						PTID:    ID1,
-						Type:    "death",
-						Date:    date,
-						Reason:  m["REASDTH"],
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-
-					// source: add path
-					d.Source.Path = append(d.Source.Path, path)
-					// check Operative
-
-					if m["SURVIVAL"] == "0" {
-						d.Operative = 1
-						// if date of surgery and date of death is not the same day
-						if operDate != date {
-							msg := errMessage{"operative", "Date of surgery is '" + operDate + "', please indicate if death was operative"}
-							d.Fix = append(d.Fix, msg)
-						}
-					} else if m["SURVIVAL"] == "1" {
-						// if date of surgery and date of death is the same day
-						if operDate == date {
-							msg := errMessage{"operative", "Date of surgery is '" + operDate + "', please indicate if death was operative"}
-							d.Fix = append(d.Fix, msg)
-						}
-					}
-
-					// if primary cause of death is not valid code
-					if !helper.CheckIntValue(&d.PrmDth, m["PRM_DTH"], nums[:6]) {
-						msg := errMessage{"primary_cause", "invalid value: '" + m["PRM_DTH"] + "'"}
-						d.Fix = append(d.Fix, msg)
-					}
-					// if status is not "D" or "N"
-					if S1 != "D" && S1 != "N" {
-						msg := errMessage{"status", "invalid value: '" + S1 + "'"}
-						d.Fix = append(d.Fix, msg)
-					}
-
-					// if no duplicates, store in a slice
-					if !(&d).CompareDeath(&allDths) {
-						allDths = append(allDths, d)
-					}
-					// est == 3 means invalid date format
-				} else if est == 3 {
-					//create a fix event
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// create msg
-					f.Msg = "Death event with invalid date format: '" + date + "'" +
-						helper.DeathNotes(m["PRM_DTH"], m["REASDTH"], operative)
-
-					// source: add path
-					f.Source.Path = append(f.Source.Path, path)
-					// if no duplicates, store in a slice of the same type
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-					// else est == 2 and at least one of the following fields is not empty,
-					// create a fix event
-				} else if !(m["PRM_DTH"] == "0" || m["PRM_DTH"] == "") || m["REASDTH"] != "" || m["DIED"] == "1" {
-
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-
-					f.Msg = "Death event with no date associated" +
-						helper.DeathNotes(m["PRM_DTH"], m["REASDTH"], operative)
-
-					// add path to source
-					f.Source.Path = append(f.Source.Path, path)
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-				}
-
-				// Event FUREOP -> Event operation
-
-				// estimate operation date
-				date, est = helper.CheckDateFormat(e, path, j, i, "FUREOP_Date", m["FUREOP_D"])
-				// create operation notes
-				opString := helper.OperationNotes(m["REASREOP"], m["REOPSURVIVAL"],
-					m["REOPNOTES"], m["REOPSURG"], m["NONVALVE REOP"])
-
-				// operation date with invalid format
-				if est == 0 || est == 1 {
-					// create an operation event
-					op := operation{
-						PTID:    ID1,
-						Type:    "operation",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to source
-					op.Source.Path = append(op.Source.Path, path)
-
-					// check the value of REOPSURVIVAL
-					var survival int
-					if !helper.CheckIntValue(&survival, m["REOPSURVIVAL"], nums[:3]) {
-						msg := errMessage{"survival", "invalid value: '" + m["REOPSURVIVAL"] + "'"}
-						op.Fix = append(op.Fix, msg)
-					}
-
-					// add operation string to fix field
-					if !(m["REASREOP"] == "" && m["REOPSURVIVAL"] == "0" && m["REOPNOTES"] == "" &&
-						m["REOPSURG"] == "" && m["NONVALVE REOP"] == "") {
-
-						msg := errMessage{"operation", opString}
-						op.Fix = append(op.Fix, msg)
-					}
-
-					// if no duplicates, store in a slice
-					if !op.CompareOperation(allOperation) {
-						allOperation = append(allOperation, op)
-					}
-					// invalid date format
-				} else if est == 3 {
-					// create a fix event
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add Msg
-					f.Msg = "Invalid REOP date format: '" + m["FUREOP_D"] + "', here is the re-operation info: " + opString
-					// add path to source
-					f.Source.Path = append(f.Source.Path, path)
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-					// when date is empty, other fields have at least one value,
-					// create a fix event
-				} else if m["FUREOP"] == "1" || m["REASREOP"] != "" || m["REOPNOTES"] != "" ||
-					m["REOPSURG"] != "" || m["NONVALVE REOP"] != "" {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add msg
-					f.Msg = "REOP fields without date associated, here is the re-operation info: " + opString
-					// add path to source
-					f.Source.Path = append(f.Source.Path, path)
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-				}
-
-				// TE1
-				// estimate TE date
-				date, est = helper.CheckDateFormat(e, path, j, i, "TE1_Date", m["TE1_D"])
-				// TE date with valid format
-				if est == 0 || est == 1 {
-					// TE code is 1 or 2, create a stroke event
-					if m["TE1"] == "1" || m["TE1"] == "2" {
-						s := te{
-							PTID:    ID1,
-							Type:    "stroke",
-							Date:    date,
-							DateEst: est,
-							Source:  source{Type: "followup", Path: []string{}}}
-
-						// add fix object if TE coded 1
-						if m["TE1"] == "1" {
-							msg := errMessage{"stroke", "coded as ‘1’, uncertain if stroke or TIA"}
-							s.Fix = append(s.Fix, msg)
-						}
-						// add path to source
-						s.Source.Path = append(s.Source.Path, path)
-
-						// if date of surgery has valid format,
-						// compare it with the TE_D to decide the value of when:
-						// if the TE date is the same day as the operation or up to 30 days after the operation, set field “when” : 1;
-						// otherwise, set field “when” : 2
-						if operEst == 0 || operEst == 1 {
-							s.When = helper.CompareDates(e, date, operDate)
-						} else {
-							msg := errMessage{"when", "cannot compare with DATEOR, it is empty or has different name."}
-							s.Fix = append(s.Fix, msg)
-							e.Println(path, "sheet:", j+1, "row:", i+2, "INFO: DATEOR is empty or has different name.")
-						}
+						// create a fix event
+						f := newFix(ID1, "lost_to_followup", fixMissingDate, r, m, "STATUS=L DATE", statusCol, "FU_D", "LKA_D", "NOTES", "FU NOTES", "STATUS=O REASON")
+						f.Msg = "The status was L but there was no date to associate with it. Notes: '" + notes + "'"
 
-						// validate outcome
-						if !helper.CheckIntValue(&s.Outcome, m["TE1_OUT"], nums[:5]) {
-							msg := errMessage{"outcome", "invalid value: '" + m["TE1_OUT"] + "'"}
-							s.Fix = append(s.Fix, msg)
-						}
-						// validate anti_agents
-						if !helper.CheckIntValue(&s.Agents, m["ANTI_TE1"], nums[:5]) && (m["ANTI_TE1"] != "8") {
-							msg := errMessage{"anti_agents", "invalid value: '" + m["ANTI_TE1"] + "'"}
-							s.Fix = append(s.Fix, msg)
-						}
-						// if no duplicates, store in a slice
-						if !s.CompareTE(allStroke) {
-							allStroke = append(allStroke, s)
-						}
-						// TE code is 3, create a tia event
-					} else if m["TE1"] == "3" {
-						t := te{
-							PTID:    ID1,
-							Type:    "tia",
-							Date:    date,
-							DateEst: est,
-							Source:  source{Type: "followup", Path: []string{}}}
-
-						// add path to source
-						t.Source.Path = append(t.Source.Path, path)
-
-						// validate outcome value
-						if !helper.CheckIntValue(&t.Outcome, m["TE1_OUT"], nums[:5]) {
-							msg := errMessage{"outcome", "invalid value: '" + m["TE1_OUT"] + "'"}
-							t.Fix = append(t.Fix, msg)
-						}
-						// validate anti_agents value
-						if !helper.CheckIntValue(&t.Agents, m["ANTI_TE1"], nums[:5]) && (m["ANTI_TE1"] != "8") {
-							msg := errMessage{"anti_agents", "invalid value: '" + m["ANTI_TE1"] + "'"}
-							t.Fix = append(t.Fix, msg)
-						}
-						// if no duplicates, store in a slice
-						if !t.CompareTE(allTIA) {
-							allTIA = append(allTIA, t)
-						}
-					}
-					// TE date is empty or with invalid format
-				} else if est == 2 || est == 3 {
-					if m["TE1"] == "1" || m["TE1"] == "2" || m["TE1"] == "3" {
-						f := general{
-							PTID:    ID1,
-							Type:    "fix",
-							Date:    "1900-01-01",
-							DateEst: 1,
-							Source:  source{Type: "followup", Path: []string{}}}
-
-						// add path to source
-						f.Source.Path = append(f.Source.Path, path)
-
-						// add Msg
-						if m["TE1"] == "1" {
-							f.Msg = "TE was coded 1 and had no valid date associated"
-						} else if m["TE1"] == "2" {
-							if est == 2 {
-								f.Msg = "stroke with missing date but code exists, " + helper.TeNotes(m["TE1_OUT"], m["ANTI_TE1"]) +
-									", when: 'not applicable because of empty date'"
-							} else if est == 3 {
-								f.Msg = "stroke with invalid date format: '" + date + "', " + helper.TeNotes(m["TE1_OUT"], m["ANTI_TE1"]) +
-									", when: 'not applicable because of invalid date format'"
-							}
-						} else if m["TE1"] == "3" {
-							if est == 2 {
-								f.Msg = "tia with missing date but code exists, " + helper.TeNotes(m["TE1_OUT"], m["ANTI_TE1"])
-							} else if est == 3 {
-								f.Msg = "tia with invalid date format: '" + date + "', " + helper.TeNotes(m["TE1_OUT"], m["ANTI_TE1"])
-							}
+						if lkaEst != 2 {
+							f.Msg += ", lka_date: '" + lkaDate + "'"
 						}
 						// if no duplicates, store in a slice
-						if !f.CompareEvents(allFix) {
+						if !f.CompareFix(allFix) {
 							allFix = append(allFix, f)
 						}
 					}
 				}
+				// if "STATUS=L DATE" has invalid date format, create a fix event
+			} else {
+				// create a fix event
+				f := newFix(ID1, "lost_to_followup", fixInvalidDate, r, m, "STATUS=L DATE", "LKA_D", "NOTES", "FU NOTES", "STATUS=O REASON")
+				f.Msg = "Invalid STATUS=L DATE: '" + date + "', Notes: '" + notes + "'"
+
+				// add lka_date
+				if lkaEst != 2 {
+					f.Msg += ", lka_date: '" + lkaDate + "'"
+				}
 
-				// TE2
-				// estimate TE date
-				date, est = helper.CheckDateFormat(e, path, j, i, "TE2_Date", m["TE2_D"])
+				// if no duplicates, store in a slice
+				if !f.CompareFix(allFix) {
+					allFix = append(allFix, f)
+				}
+			}
+		}
 
-				// TE date with valid format
-				if est == 0 || est == 1 {
-					// TE code is 1 or 2, create a stroke event
-					if m["TE2"] == "1" || m["TE2"] == "2" {
-						s := te{
-							PTID:    ID1,
-							Type:    "stroke",
-							Date:    date,
-							DateEst: est,
-							Source:  source{Type: "followup", Path: []string{}}}
-
-						// add fix object if TE coded 1
-						if m["TE2"] == "1" {
-							msg := errMessage{"stroke", "coded as ‘1’, uncertain if stroke or TIA"}
-							s.Fix = append(s.Fix, msg)
-						}
+		// Event Death
 
-						// add path to source
-						s.Source.Path = append(s.Source.Path, path)
-
-						// if date of surgery has valid format,
-						// compare it with the TE_D to decide the value of when:
-						// if the TE date is the same day as the operation or up to 30 days after the operation, set field “when” : 1;
-						// otherwise, set field “when” : 2
-						if operEst == 0 || operEst == 1 {
-							s.When = helper.CompareDates(e, date, operDate)
-						} else {
-							msg := errMessage{"when", "cannot compare with DATEOR, it is empty or has different name."}
-							s.Fix = append(s.Fix, msg)
-							e.Println(path, "sheet:", j+1, "row:", i+2, "INFO: DATEOR is empty or has different name.")
-						}
+		// estimate death date
+		date, est = helper.CheckDateFormat(e, path, j, i, "DTH_Date", m["DTH_D"])
 
-						// validate outcome
-						if !helper.CheckIntValue(&s.Outcome, m["TE2_OUT"], nums[:5]) {
-							msg := errMessage{"outcome", "invalid value: '" + m["TE2_OUT"] + "'"}
-							s.Fix = append(s.Fix, msg)
-						}
-						// validate anti_agents
-						if !helper.CheckIntValue(&s.Agents, m["ANTI_TE2"], nums[:5]) && (m["ANTI_TE2"] != "8") {
-							msg := errMessage{"anti_agents", "invalid value: '" + m["ANTI_TE2"] + "'"}
-							s.Fix = append(s.Fix, msg)
-						}
-						// if no duplicates, store in a slice
-						if !s.CompareTE(allStroke) {
-							allStroke = append(allStroke, s)
-						}
-						// TE code is 3, create a tia event
-					} else if m["TE2"] == "3" {
-						t := te{
-							PTID:    ID1,
-							Type:    "tia",
-							Date:    date,
-							DateEst: est,
-							Source:  source{Type: "followup", Path: []string{}}}
-
-						// add path to source
-						t.Source.Path = append(t.Source.Path, path)
-
-						// validate outcome value
-						if !helper.CheckIntValue(&t.Outcome, m["TE2_OUT"], nums[:5]) {
-							msg := errMessage{"outcome", "invalid value: '" + m["TE2_OUT"] + "'"}
-							t.Fix = append(t.Fix, msg)
-						}
-						// validate anti_agents value
-						if !helper.CheckIntValue(&t.Agents, m["ANTI_TE2"], nums[:5]) && (m["ANTI_TE2"] != "8") {
-							msg := errMessage{"anti_agents", "invalid value: '" + m["ANTI_TE2"] + "'"}
-							t.Fix = append(t.Fix, msg)
-						}
-						// if no duplicates, store in a slice
-						if !t.CompareTE(allTIA) {
-							allTIA = append(allTIA, t)
-						}
-					}
-					// TE date is empty or with invalid format
-				} else if est == 2 || est == 3 {
-					if m["TE2"] == "1" || m["TE2"] == "2" || m["TE2"] == "3" {
-						f := general{
-							PTID:    ID1,
-							Type:    "fix",
-							Date:    "1900-01-01",
-							DateEst: 1,
-							Source:  source{Type: "followup", Path: []string{}}}
-
-						// add path to source
-						f.Source.Path = append(f.Source.Path, path)
-
-						// add Msg
-						if m["TE2"] == "1" {
-							f.Msg = "TE was coded 1 and had no valid date associated"
-						} else if m["TE2"] == "2" {
-							if est == 2 {
-								f.Msg = "stroke with missing date but code exists, " + helper.TeNotes(m["TE2_OUT"], m["ANTI_TE2"]) +
-									", when: 'not applicable because of empty date'"
-							} else if est == 3 {
-								f.Msg = "stroke with invalid date format: '" + date + "', " + helper.TeNotes(m["TE2_OUT"], m["ANTI_TE2"]) +
-									", when: 'not applicable because of invalid date format'"
-							}
-						} else if m["TE2"] == "3" {
-							if est == 2 {
-								f.Msg = "tia with missing date but code exists, " + helper.TeNotes(m["TE2_OUT"], m["ANTI_TE2"])
-							} else if est == 3 {
-								f.Msg = "tia with invalid date format: '" + date + "', " + helper.TeNotes(m["TE2_OUT"], m["ANTI_TE2"])
-							}
-						}
-						// if no duplicates, store in a slice
-						if !f.CompareEvents(allFix) {
-							allFix = append(allFix, f)
-						}
-					}
+		// assign operative
+		var operative string
+		if m["SURVIVAL"] == "0" {
+			operative = "1"
+		} else {
+			operative = "0"
+		}
+		// death date with valid format
+		if est == 0 || est == 1 {
+			d := death{
+				PTID:    ID1,
+				Type:    "death",
+				Date:    date,
+				Reason:  m["REASDTH"],
+				DateEst: est,
+				Source:  newSource(r, m, "DTH_D", "DIED", "REASDTH", "PRM_DTH", "SURVIVAL")}
+
+			// the coded operative, setOperative checks it against the dates of the operations
+			if m["SURVIVAL"] == "0" || m["SURVIVAL"] == "1" {
+				coded := 0
+				if m["SURVIVAL"] == "0" {
+					coded = 1
 				}
+				d.Operative, d.CodedOp = coded, &coded
+			}
 
-				// TE3
-				// estimate TE date
-				date, est = helper.CheckDateFormat(e, path, j, i, "TE3_Date", m["TE3_D"])
-				// TE date with valid format
-				if est == 0 || est == 1 {
-					// TE code is 1 or 2, create a stroke event
-					if m["TE3"] == "1" || m["TE3"] == "2" {
-						s := te{
-							PTID:    ID1,
-							Type:    "stroke",
-							Date:    date,
-							DateEst: est,
-							Source:  source{Type: "followup", Path: []string{}}}
-
-						// add fix object if TE coded 1
-						if m["TE3"] == "1" {
-							msg := errMessage{"stroke", "coded as ‘1’, uncertain if stroke or TIA"}
-							s.Fix = append(s.Fix, msg)
-						}
+			// if primary cause of death is not valid code
+			if !helper.CheckIntValue(&d.PrmDth, m["PRM_DTH"], nums[:6]) {
+				msg := errMessage{"primary_cause", "invalid value: '" + m["PRM_DTH"] + "'"}
+				d.Fix = append(d.Fix, msg)
+			}
+			// if status is not "D" or "N"
+			if S1 != "D" && S1 != "N" {
+				msg := errMessage{"status", "invalid value: '" + S1 + "'"}
+				d.Fix = append(d.Fix, msg)
+			}
 
-						// add path to source
-						s.Source.Path = append(s.Source.Path, path)
+			// if no duplicates, store in a slice
+			if !(&d).CompareDeath(&allDths) {
+				allDths = append(allDths, d)
+			}
+			// est == 3 means invalid date format
+		} else if est == 3 {
+			//create a fix event
+			f := newFix(ID1, "death", fixInvalidDate, r, m, "DTH_D", "DIED", "REASDTH", "PRM_DTH", "SURVIVAL")
+			// create msg
+			f.Msg = "Death event with invalid date format: '" + date + "'" +
+				helper.DeathNotes(m["PRM_DTH"], m["REASDTH"], operative)
+
+			// if no duplicates, store in a slice of the same type
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+			// else est == 2 and at least one of the following fields is not empty,
+			// create a fix event
+		} else if !(m["PRM_DTH"] == "0" || m["PRM_DTH"] == "") || m["REASDTH"] != "" || m["DIED"] == "1" {
 
-						// if date of surgery has valid format,
-						// compare it with the TE_D to decide the value of when:
-						// if the TE date is the same day as the operation or up to 30 days after the operation, set field “when” : 1;
-						// otherwise, set field “when” : 2
-						if operEst == 0 || operEst == 1 {
+			f := newFix(ID1, "death", fixMissingDate, r, m, "DTH_D", "DIED", "REASDTH", "PRM_DTH", "SURVIVAL")
 
-							s.When = helper.CompareDates(e, date, operDate)
-						} else {
-							msg := errMessage{"when", "cannot compare with DATEOR, it is empty or has different name."}
-							s.Fix = append(s.Fix, msg)
-							e.Println(path, "sheet:", j+1, "row:", i+2, "INFO: DATEOR is empty or has different name.")
-						}
+			f.Msg = "Death event with no date associated" +
+				helper.DeathNotes(m["PRM_DTH"], m["REASDTH"], operative)
 
-						// validate outcome
-						if !helper.CheckIntValue(&s.Outcome, m["TE3_OUT"], nums[:5]) {
-							msg := errMessage{"outcome", "invalid value: '" + m["TE3_OUT"] + "'"}
-							s.Fix = append(s.Fix, msg)
-						}
-						// validate anti_agents
-						if !helper.CheckIntValue(&s.Agents, m["ANTI_TE3"], nums[:5]) && (m["ANTI_TE3"] != "8") {
-							msg := errMessage{"anti_agents", "invalid value: '" + m["ANTI_TE3"] + "'"}
-							s.Fix = append(s.Fix, msg)
-						}
-						// if no duplicates, store in a slice
-						if !s.CompareTE(allStroke) {
-							allStroke = append(allStroke, s)
-						}
-						// TE code is 3, create a tia event
-					} else if m["TE3"] == "3" {
-						t := te{
-							PTID:    ID1,
-							Type:    "tia",
-							Date:    date,
-							DateEst: est,
-							Source:  source{Type: "followup", Path: []string{}}}
-
-						// add path to source
-						t.Source.Path = append(t.Source.Path, path)
-
-						// validate outcome value
-						if !helper.CheckIntValue(&t.Outcome, m["TE3_OUT"], nums[:5]) {
-							msg := errMessage{"outcome", "invalid value: '" + m["TE3_OUT"] + "'"}
-							t.Fix = append(t.Fix, msg)
-						}
-						// validate anti_agents value
-						if !helper.CheckIntValue(&t.Agents, m["ANTI_TE3"], nums[:5]) && (m["ANTI_TE3"] != "8") {
-							msg := errMessage{"anti_agents", "invalid value: '" + m["ANTI_TE3"] + "'"}
-							t.Fix = append(t.Fix, msg)
-						}
-						// if no duplicates, store in a slice
-						if !t.CompareTE(allTIA) {
-							allTIA = append(allTIA, t)
-						}
-					}
-					// TE date is empty or with invalid format
-				} else if est == 2 || est == 3 {
-					if m["TE3"] == "1" || m["TE3"] == "2" || m["TE3"] == "3" {
-						f := general{
-							PTID:    ID1,
-							Type:    "fix",
-							Date:    "1900-01-01",
-							DateEst: 1,
-							Source:  source{Type: "followup", Path: []string{}}}
-
-						// add path to source
-						f.Source.Path = append(f.Source.Path, path)
-
-						// add Msg
-						if m["TE3"] == "1" {
-							f.Msg = "TE was coded 1 and had no valid date associated."
-						} else if m["TE3"] == "2" {
-							if est == 2 {
-								f.Msg = "stroke with missing date but code exists, " + helper.TeNotes(m["TE3_OUT"], m["ANTI_TE3"]) +
-									", when: 'not applicable because of empty date'"
-							} else if est == 3 {
-								f.Msg = "stroke with invalid date format: '" + date + "', " + helper.TeNotes(m["TE3_OUT"], m["ANTI_TE3"]) +
-									", when: 'not applicable because of invalid date format'"
-							}
-						} else if m["TE3"] == "3" {
-							if est == 2 {
-								f.Msg = "tia with missing date but code exists, " + helper.TeNotes(m["TE3_OUT"], m["ANTI_TE3"])
-							} else if est == 3 {
-								f.Msg = "tia with invalid date format: '" + date + "', " + helper.TeNotes(m["TE3_OUT"], m["ANTI_TE3"])
-							}
-						}
-						// if no duplicates, store in a slice
-						if !f.CompareEvents(allFix) {
-							allFix = append(allFix, f)
-						}
-					}
-				}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-				// Event FUMI
-				// estimate date value and format
-				date, est = helper.CheckDateFormat(e, path, j, i, "FUMI_Date", m["FUMI_D"])
-				// FUMI date has valid format
-				if est == 0 || est == 1 {
-					// create a myocardial_infarction event
-					mi := general{
-						PTID:    ID1,
-						Type:    "myocardial_infarction",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to source
-					mi.Source.Path = append(mi.Source.Path, path)
+		// Event FUREOP -> Event operation
+
+		// estimate operation date
+		date, est = helper.CheckDateFormat(e, path, j, i, "FUREOP_Date", m["FUREOP_D"])
+		// create operation notes
+		opString := helper.OperationNotes(m["REASREOP"], m["REOPSURVIVAL"],
+			m["REOPNOTES"], m["REOPSURG"], m["NONVALVE REOP"])
+
+		// operation date with invalid format
+		if est == 0 || est == 1 {
+			// create an operation event
+			op := operation{
+				PTID:    ID1,
+				Type:    "operation",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "FUREOP_D", "FUREOP", "REASREOP", "REOPSURVIVAL", "REOPNOTES", "REOPSURG", "NONVALVE REOP")}
+
+			// check the value of REOPSURVIVAL
+			var survival int
+			if !helper.CheckIntValue(&survival, m["REOPSURVIVAL"], nums[:3]) {
+				msg := errMessage{"survival", "invalid value: '" + m["REOPSURVIVAL"] + "'"}
+				op.Fix = append(op.Fix, msg)
+			} else if strings.TrimSpace(m["REOPSURVIVAL"]) != "" {
+				op.Survival = &survival
+			}
 
-					// if no duplicates, store in a slice
-					if !mi.CompareEvents(allFUMI) {
-						allFUMI = append(allFUMI, mi)
-					}
-					// invalid date format or date is empty,
-					// create a fix event
-				} else if est == 3 || (est == 2 && m["FUMI"] == "1") {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to source
-					f.Source.Path = append(f.Source.Path, path)
-					// add Msg
-					if est == 3 {
-						f.Msg = "FUMI with invalid date format: '" + date + "'"
-					} else {
-						f.Msg = "FUMI with no date but code is 1."
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
+			// the reason, the surgery and the notes of the re-operation
+			if v := strings.TrimSpace(m["REASREOP"]); v != "" {
+				op.Reason = &v
+			}
+			if v := strings.TrimSpace(m["REOPSURG"]); v != "" {
+				op.Surgery = &v
+				var msg *errMessage
+				if op.Surgeries, msg = parseSurgeries(v); msg != nil {
+					op.Fix = append(op.Fix, *msg)
 				}
+			}
+			if v := strings.TrimSpace(m["REOPNOTES"]); v != "" {
+				op.Notes = &v
+			}
+			if v := strings.TrimSpace(m["NONVALVE REOP"]); v != "" {
+				v = "Nonvalve re-op: " + v
+				if op.Notes != nil {
+					v = *op.Notes + ", " + v
+				}
+				op.Notes = &v
+			}
 
-				// Event FUPACE
+			// if no duplicates, store in a slice
+			if !op.CompareOperation(allOperation) {
+				allOperation = append(allOperation, op)
+			}
+			// invalid date format
+		} else if est == 3 {
+			// create a fix event
+			f := newFix(ID1, "operation", fixInvalidDate, r, m, "FUREOP_D", "FUREOP", "REASREOP", "REOPSURVIVAL", "REOPNOTES", "REOPSURG", "NONVALVE REOP")
+			// add Msg
+			f.Msg = "Invalid REOP date format: '" + m["FUREOP_D"] + "', here is the re-operation info: " + opString
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+			// when date is empty, other fields have at least one value,
+			// create a fix event
+		} else if m["FUREOP"] == "1" || m["REASREOP"] != "" || m["REOPNOTES"] != "" ||
+			m["REOPSURG"] != "" || m["NONVALVE REOP"] != "" {
+			f := newFix(ID1, "operation", fixMissingDate, r, m, "FUREOP_D", "FUREOP", "REASREOP", "REOPSURVIVAL", "REOPNOTES", "REOPSURG", "NONVALVE REOP")
+			// add msg
+			f.Msg = "REOP fields without date associated, here is the re-operation info: " + opString
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-				// estimate date's value and format
-				date, est = helper.CheckDateFormat(e, path, j, i, "FUPACE_Date", m["FUPACE_D"])
-				// date has valid format,
-				// create a perm_pacemaker event
-				if est == 0 || est == 1 {
-					pace := general{
-						PTID:    ID1,
-						Type:    "perm_pacemaker",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
+		// TE1
+		// estimate TE date
+		date, est = helper.CheckDateFormat(e, path, j, i, "TE1_Date", m["TE1_D"])
+		// TE date with valid format
+		if est == 0 || est == 1 {
+			// TE code is 1 or 2, create a stroke event
+			if m["TE1"] == "1" || m["TE1"] == "2" {
+				s := te{
+					PTID:    ID1,
+					Type:    "stroke",
+					Date:    date,
+					DateEst: est,
+					Source:  newSource(r, m, "TE1_D", "TE1", "TE1_OUT", "ANTI_TE1")}
+
+				// add fix object if TE coded 1
+				if m["TE1"] == "1" {
+					msg := errMessage{"stroke", "coded as ‘1’, uncertain if stroke or TIA"}
+					s.Fix = append(s.Fix, msg)
+				}
 
-					// add path to the source
-					pace.Source.Path = append(pace.Source.Path, path)
+				// when is set by setWhen from the nearest preceding operation
 
-					// if no duplicates, store in a slice
-					if !pace.CompareEvents(allFUPACE) {
-						allFUPACE = append(allFUPACE, pace)
-					}
-					// if date is empty or has invalid format,
-					// create a fix event
-				} else if (est == 2 && m["FUPACE"] == "1") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					f.Source.Path = append(f.Source.Path, path)
-					// add Msg
-					if est == 3 {
-						f.Msg = "FUPACE with invalid date format: '" + date + "'"
-					} else if m["FUPACE"] == "1" {
-						f.Msg = "FUPACE with no date but code is 1."
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
+				// validate outcome
+				if !helper.CheckIntValue(&s.Outcome, m["TE1_OUT"], nums[:5]) {
+					msg := errMessage{"outcome", "invalid value: '" + m["TE1_OUT"] + "'"}
+					s.Fix = append(s.Fix, msg)
+				}
+				// validate anti_agents
+				if !helper.CheckIntValue(&s.Agents, m["ANTI_TE1"], nums[:5]) && (m["ANTI_TE1"] != "8") {
+					msg := errMessage{"anti_agents", "invalid value: '" + m["ANTI_TE1"] + "'"}
+					s.Fix = append(s.Fix, msg)
+				}
+				// if no duplicates, store in a slice
+				if !s.CompareTE(allStroke) {
+					allStroke = append(allStroke, s)
+				}
+				// TE code is 3, create a tia event
+			} else if m["TE1"] == "3" {
+				t := te{
+					PTID:    ID1,
+					Type:    "tia",
+					Date:    date,
+					DateEst: est,
+					Source:  newSource(r, m, "TE1_D", "TE1", "TE1_OUT", "ANTI_TE1")}
+
+				// validate outcome value
+				if !helper.CheckIntValue(&t.Outcome, m["TE1_OUT"], nums[:5]) {
+					msg := errMessage{"outcome", "invalid value: '" + m["TE1_OUT"] + "'"}
+					t.Fix = append(t.Fix, msg)
+				}
+				// validate anti_agents value
+				if !helper.CheckIntValue(&t.Agents, m["ANTI_TE1"], nums[:5]) && (m["ANTI_TE1"] != "8") {
+					msg := errMessage{"anti_agents", "invalid value: '" + m["ANTI_TE1"] + "'"}
+					t.Fix = append(t.Fix, msg)
+				}
+				// if no duplicates, store in a slice
+				if !t.CompareTE(allTIA) {
+					allTIA = append(allTIA, t)
+				}
+				// TE code is neither a stroke nor a tia, create a fix event
+			} else if m["TE1"] != "" && m["TE1"] != "0" {
+				f := newFix(ID1, "stroke", fixInvalidCode, r, m, "TE1", "TE1_D", "TE1_OUT", "ANTI_TE1")
+				// add Msg
+				f.Msg = "TE with date but code is not 1, 2 or 3: '" + m["TE1"] + "'"
+				// if no duplicates, store in a slice
+				if !f.CompareFix(allFix) {
+					allFix = append(allFix, f)
+				}
+			}
+			// TE date is empty or with invalid format
+		} else if est == 2 || est == 3 {
+			if m["TE1"] == "1" || m["TE1"] == "2" || m["TE1"] == "3" {
+				f := newFix(ID1, teEventType(m["TE1"]), fixReason(est), r, m, "TE1_D", "TE1", "TE1_OUT", "ANTI_TE1")
+
+				// add Msg
+				if m["TE1"] == "1" {
+					f.Msg = "TE was coded 1 and had no valid date associated"
+				} else if m["TE1"] == "2" {
+					if est == 2 {
+						f.Msg = "stroke with missing date but code exists, " + helper.TeNotes(m["TE1_OUT"], m["ANTI_TE1"]) +
+							", when: 'not applicable because of empty date'"
+					} else if est == 3 {
+						f.Msg = "stroke with invalid date format: '" + date + "', " + helper.TeNotes(m["TE1_OUT"], m["ANTI_TE1"]) +
+							", when: 'not applicable because of invalid date format'"
+					}
+				} else if m["TE1"] == "3" {
+					if est == 2 {
+						f.Msg = "tia with missing date but code exists, " + helper.TeNotes(m["TE1_OUT"], m["ANTI_TE1"])
+					} else if est == 3 {
+						f.Msg = "tia with invalid date format: '" + date + "', " + helper.TeNotes(m["TE1_OUT"], m["ANTI_TE1"])
 					}
 				}
+				// if no duplicates, store in a slice
+				if !f.CompareFix(allFix) {
+					allFix = append(allFix, f)
+				}
+			}
+		}
 
-				// Event SBE
-				// estimate date's value and format
-				date, est = helper.CheckDateFormat(e, path, j, i, "SBE1_Date", m["SBE1_D"])
-				// get value for Organism
-				ORGANISM := m["SBE1 ORGANISM"]
-				organism := m["SBE1 organism"]
-				// if date has valid format, create a sbe event
-				if est == 0 || est == 1 {
-					sbe1 := general{
-						PTID:    ID1,
-						Type:    "sbe",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-
-					// add path to the source
-					sbe1.Source.Path = append(sbe1.Source.Path, path)
-
-					// assign value to Organism
-					// some sheets may have organism instead of ORGANISM
-					if ORGANISM != "" {
-						sbe1.Organism = &ORGANISM
-					} else {
-						sbe1.Organism = &organism
-					}
+		// TE2
+		// estimate TE date
+		date, est = helper.CheckDateFormat(e, path, j, i, "TE2_Date", m["TE2_D"])
+
+		// TE date with valid format
+		if est == 0 || est == 1 {
+			// TE code is 1 or 2, create a stroke event
+			if m["TE2"] == "1" || m["TE2"] == "2" {
+				s := te{
+					PTID:    ID1,
+					Type:    "stroke",
+					Date:    date,
+					DateEst: est,
+					Source:  newSource(r, m, "TE2_D", "TE2", "TE2_OUT", "ANTI_TE2")}
+
+				// add fix object if TE coded 1
+				if m["TE2"] == "1" {
+					msg := errMessage{"stroke", "coded as ‘1’, uncertain if stroke or TIA"}
+					s.Fix = append(s.Fix, msg)
+				}
 
-					// 	Check Organism
-					if *sbe1.Organism == "" {
-						sbe1.Organism = nil
-					} else if !helper.CheckStringValue(*sbe1.Organism) {
-						msg := errMessage{"organism", "invalid organism value: '" + *sbe1.Organism + "'"}
-						sbe1.Fix = append(sbe1.Fix, msg)
-					}
+				// when is set by setWhen from the nearest preceding operation
 
-					// if no duplicates, store in a slice
-					if !sbe1.CompareEvents(allSBE) {
-						allSBE = append(allSBE, sbe1)
-					}
-					// if date is empty or has invalid format, create a fix event
-				} else if (est == 2 && m["SBE1"] == "1") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					f.Source.Path = append(f.Source.Path, path)
-					// check for Organism
-					if ORGANISM != "" {
-						organism = ORGANISM
-					}
-					// add Msg
-					if est == 3 {
-						f.Msg = "SBE with invalid date format: '" + date + "', organism: '" + organism + "'"
-					} else if m["SBE1"] == "1" {
-						f.Msg = "SBE with no date but code is 1, code: '" + m["SBE1"] + "', organism: '" + organism + "'"
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
+				// validate outcome
+				if !helper.CheckIntValue(&s.Outcome, m["TE2_OUT"], nums[:5]) {
+					msg := errMessage{"outcome", "invalid value: '" + m["TE2_OUT"] + "'"}
+					s.Fix = append(s.Fix, msg)
+				}
+				// validate anti_agents
+				if !helper.CheckIntValue(&s.Agents, m["ANTI_TE2"], nums[:5]) && (m["ANTI_TE2"] != "8") {
+					msg := errMessage{"anti_agents", "invalid value: '" + m["ANTI_TE2"] + "'"}
+					s.Fix = append(s.Fix, msg)
+				}
+				// if no duplicates, store in a slice
+				if !s.CompareTE(allStroke) {
+					allStroke = append(allStroke, s)
+				}
+				// TE code is 3, create a tia event
+			} else if m["TE2"] == "3" {
+				t := te{
+					PTID:    ID1,
+					Type:    "tia",
+					Date:    date,
+					DateEst: est,
+					Source:  newSource(r, m, "TE2_D", "TE2", "TE2_OUT", "ANTI_TE2")}
+
+				// validate outcome value
+				if !helper.CheckIntValue(&t.Outcome, m["TE2_OUT"], nums[:5]) {
+					msg := errMessage{"outcome", "invalid value: '" + m["TE2_OUT"] + "'"}
+					t.Fix = append(t.Fix, msg)
+				}
+				// validate anti_agents value
+				if !helper.CheckIntValue(&t.Agents, m["ANTI_TE2"], nums[:5]) && (m["ANTI_TE2"] != "8") {
+					msg := errMessage{"anti_agents", "invalid value: '" + m["ANTI_TE2"] + "'"}
+					t.Fix = append(t.Fix, msg)
+				}
+				// if no duplicates, store in a slice
+				if !t.CompareTE(allTIA) {
+					allTIA = append(allTIA, t)
+				}
+				// TE code is neither a stroke nor a tia, create a fix event
+			} else if m["TE2"] != "" && m["TE2"] != "0" {
+				f := newFix(ID1, "stroke", fixInvalidCode, r, m, "TE2", "TE2_D", "TE2_OUT", "ANTI_TE2")
+				// add Msg
+				f.Msg = "TE with date but code is not 1, 2 or 3: '" + m["TE2"] + "'"
+				// if no duplicates, store in a slice
+				if !f.CompareFix(allFix) {
+					allFix = append(allFix, f)
+				}
+			}
+			// TE date is empty or with invalid format
+		} else if est == 2 || est == 3 {
+			if m["TE2"] == "1" || m["TE2"] == "2" || m["TE2"] == "3" {
+				f := newFix(ID1, teEventType(m["TE2"]), fixReason(est), r, m, "TE2_D", "TE2", "TE2_OUT", "ANTI_TE2")
+
+				// add Msg
+				if m["TE2"] == "1" {
+					f.Msg = "TE was coded 1 and had no valid date associated"
+				} else if m["TE2"] == "2" {
+					if est == 2 {
+						f.Msg = "stroke with missing date but code exists, " + helper.TeNotes(m["TE2_OUT"], m["ANTI_TE2"]) +
+							", when: 'not applicable because of empty date'"
+					} else if est == 3 {
+						f.Msg = "stroke with invalid date format: '" + date + "', " + helper.TeNotes(m["TE2_OUT"], m["ANTI_TE2"]) +
+							", when: 'not applicable because of invalid date format'"
+					}
+				} else if m["TE2"] == "3" {
+					if est == 2 {
+						f.Msg = "tia with missing date but code exists, " + helper.TeNotes(m["TE2_OUT"], m["ANTI_TE2"])
+					} else if est == 3 {
+						f.Msg = "tia with invalid date format: '" + date + "', " + helper.TeNotes(m["TE2_OUT"], m["ANTI_TE2"])
 					}
 				}
+				// if no duplicates, store in a slice
+				if !f.CompareFix(allFix) {
+					allFix = append(allFix, f)
+				}
+			}
+		}
 
-				// SBE2
-				// estimate date value and format
-				date, est = helper.CheckDateFormat(e, path, j, i, "SBE2_Date", m["SBE2_D"])
-				// get value for Organism
-				ORGANISM = m["SBE2 ORGANISM"]
-				organism = m["SBE2 organism"]
-				// if date has valid format, create a sbe event
-				if est == 0 || est == 1 {
-					sbe2 := general{
-						PTID:    ID1,
-						Type:    "sbe",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					sbe2.Source.Path = append(sbe2.Source.Path, path)
-
-					// assign value to Organism
-					// some sheets may have organism instead of ORGANISM
-					if ORGANISM != "" {
-						sbe2.Organism = &ORGANISM
-					} else {
-						sbe2.Organism = &organism
-					}
+		// TE3
+		// estimate TE date
+		date, est = helper.CheckDateFormat(e, path, j, i, "TE3_Date", m["TE3_D"])
+		// TE date with valid format
+		if est == 0 || est == 1 {
+			// TE code is 1 or 2, create a stroke event
+			if m["TE3"] == "1" || m["TE3"] == "2" {
+				s := te{
+					PTID:    ID1,
+					Type:    "stroke",
+					Date:    date,
+					DateEst: est,
+					Source:  newSource(r, m, "TE3_D", "TE3", "TE3_OUT", "ANTI_TE3")}
+
+				// add fix object if TE coded 1
+				if m["TE3"] == "1" {
+					msg := errMessage{"stroke", "coded as ‘1’, uncertain if stroke or TIA"}
+					s.Fix = append(s.Fix, msg)
+				}
 
-					// 	Check Organism
-					if *sbe2.Organism == "" {
-						sbe2.Organism = nil
-					} else if !helper.CheckStringValue(*sbe2.Organism) {
-						msg := errMessage{"organism", "invalid organism value: '" + *sbe2.Organism + "'"}
-						sbe2.Fix = append(sbe2.Fix, msg)
-					}
+				// when is set by setWhen from the nearest preceding operation
 
-					// if no duplicates, store in a slice
-					if !sbe2.CompareEvents(allSBE) {
-						allSBE = append(allSBE, sbe2)
-					}
-					// if date is empty or has invalid format, create a fix event
-				} else if (est == 2 && m["SBE2"] == "1") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					f.Source.Path = append(f.Source.Path, path)
-					// check for Organism
-					if ORGANISM != "" {
-						organism = ORGANISM
-					}
-					// add Msg
-					if est == 3 {
-						f.Msg = "SBE with invalid date format: '" + date + "', organism: '" + organism + "'"
-					} else if m["SBE2"] == "1" {
-						f.Msg = "SBE with no date but code is 1, organism: '" + organism + "'"
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
+				// validate outcome
+				if !helper.CheckIntValue(&s.Outcome, m["TE3_OUT"], nums[:5]) {
+					msg := errMessage{"outcome", "invalid value: '" + m["TE3_OUT"] + "'"}
+					s.Fix = append(s.Fix, msg)
+				}
+				// validate anti_agents
+				if !helper.CheckIntValue(&s.Agents, m["ANTI_TE3"], nums[:5]) && (m["ANTI_TE3"] != "8") {
+					msg := errMessage{"anti_agents", "invalid value: '" + m["ANTI_TE3"] + "'"}
+					s.Fix = append(s.Fix, msg)
+				}
+				// if no duplicates, store in a slice
+				if !s.CompareTE(allStroke) {
+					allStroke = append(allStroke, s)
+				}
+				// TE code is 3, create a tia event
+			} else if m["TE3"] == "3" {
+				t := te{
+					PTID:    ID1,
+					Type:    "tia",
+					Date:    date,
+					DateEst: est,
+					Source:  newSource(r, m, "TE3_D", "TE3", "TE3_OUT", "ANTI_TE3")}
+
+				// validate outcome value
+				if !helper.CheckIntValue(&t.Outcome, m["TE3_OUT"], nums[:5]) {
+					msg := errMessage{"outcome", "invalid value: '" + m["TE3_OUT"] + "'"}
+					t.Fix = append(t.Fix, msg)
+				}
+				// validate anti_agents value
+				if !helper.CheckIntValue(&t.Agents, m["ANTI_TE3"], nums[:5]) && (m["ANTI_TE3"] != "8") {
+					msg := errMessage{"anti_agents", "invalid value: '" + m["ANTI_TE3"] + "'"}
+					t.Fix = append(t.Fix, msg)
+				}
+				// if no duplicates, store in a slice
+				if !t.CompareTE(allTIA) {
+					allTIA = append(allTIA, t)
+				}
+				// TE code is neither a stroke nor a tia, create a fix event
+			} else if m["TE3"] != "" && m["TE3"] != "0" {
+				f := newFix(ID1, "stroke", fixInvalidCode, r, m, "TE3", "TE3_D", "TE3_OUT", "ANTI_TE3")
+				// add Msg
+				f.Msg = "TE with date but code is not 1, 2 or 3: '" + m["TE3"] + "'"
+				// if no duplicates, store in a slice
+				if !f.CompareFix(allFix) {
+					allFix = append(allFix, f)
+				}
+			}
+			// TE date is empty or with invalid format
+		} else if est == 2 || est == 3 {
+			if m["TE3"] == "1" || m["TE3"] == "2" || m["TE3"] == "3" {
+				f := newFix(ID1, teEventType(m["TE3"]), fixReason(est), r, m, "TE3_D", "TE3", "TE3_OUT", "ANTI_TE3")
+
+				// add Msg
+				if m["TE3"] == "1" {
+					f.Msg = "TE was coded 1 and had no valid date associated."
+				} else if m["TE3"] == "2" {
+					if est == 2 {
+						f.Msg = "stroke with missing date but code exists, " + helper.TeNotes(m["TE3_OUT"], m["ANTI_TE3"]) +
+							", when: 'not applicable because of empty date'"
+					} else if est == 3 {
+						f.Msg = "stroke with invalid date format: '" + date + "', " + helper.TeNotes(m["TE3_OUT"], m["ANTI_TE3"]) +
+							", when: 'not applicable because of invalid date format'"
+					}
+				} else if m["TE3"] == "3" {
+					if est == 2 {
+						f.Msg = "tia with missing date but code exists, " + helper.TeNotes(m["TE3_OUT"], m["ANTI_TE3"])
+					} else if est == 3 {
+						f.Msg = "tia with invalid date format: '" + date + "', " + helper.TeNotes(m["TE3_OUT"], m["ANTI_TE3"])
 					}
 				}
+				// if no duplicates, store in a slice
+				if !f.CompareFix(allFix) {
+					allFix = append(allFix, f)
+				}
+			}
+		}
 
-				// SBE3
-				// estimate date value and format
-				date, est = helper.CheckDateFormat(e, path, j, i, "SBE3_Date", m["SBE3_D"])
-				// get value for Organism
-				ORGANISM = m["SBE3 ORGANISM"]
-				organism = m["SBE3 organism"]
-				// if date has valid format, create a sbe event
-				if est == 0 || est == 1 {
-					sbe3 := general{
-						PTID:    ID1,
-						Type:    "sbe",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// Source: add path
-					sbe3.Source.Path = append(sbe3.Source.Path, path)
-
-					// assign value to Organism
-					// some sheets may have organism instead of ORGANISM
-					if ORGANISM != "" {
-						sbe3.Organism = &ORGANISM
-					} else {
-						sbe3.Organism = &organism
-					}
+		// Event FUMI
+		// estimate date value and format
+		date, est = helper.CheckDateFormat(e, path, j, i, "FUMI_Date", m["FUMI_D"])
+		// FUMI date has valid format
+		if est == 0 || est == 1 {
+			// create a myocardial_infarction event
+			mi := general{
+				PTID:    ID1,
+				Type:    "myocardial_infarction",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "FUMI_D", "FUMI")}
+
+			// if no duplicates, store in a slice
+			if !mi.CompareEvents(allFUMI) {
+				allFUMI = append(allFUMI, mi)
+			}
+			// invalid date format or date is empty,
+			// create a fix event
+		} else if est == 3 || (est == 2 && m["FUMI"] == "1") {
+			f := newFix(ID1, "myocardial_infarction", fixReason(est), r, m, "FUMI_D", "FUMI")
+			// add Msg
+			if est == 3 {
+				f.Msg = "FUMI with invalid date format: '" + date + "'"
+			} else {
+				f.Msg = "FUMI with no date but code is 1."
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-					// 	Check Organism
-					if *sbe3.Organism == "" {
-						sbe3.Organism = nil
-					} else if !helper.CheckStringValue(*sbe3.Organism) {
-						msg := errMessage{"organism", "invalid organism value: '" + *sbe3.Organism + "'"}
-						sbe3.Fix = append(sbe3.Fix, msg)
-					}
+		// Event FUPACE
+
+		// estimate date's value and format
+		date, est = helper.CheckDateFormat(e, path, j, i, "FUPACE_Date", m["FUPACE_D"])
+		// date has valid format,
+		// create a perm_pacemaker event
+		if est == 0 || est == 1 {
+			pace := general{
+				PTID:    ID1,
+				Type:    "perm_pacemaker",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "FUPACE_D", "FUPACE")}
+
+			// if no duplicates, store in a slice
+			if !pace.CompareEvents(allFUPACE) {
+				allFUPACE = append(allFUPACE, pace)
+			}
+			// if date is empty or has invalid format,
+			// create a fix event
+		} else if (est == 2 && m["FUPACE"] == "1") || est == 3 {
+			f := newFix(ID1, "perm_pacemaker", fixReason(est), r, m, "FUPACE_D", "FUPACE")
+			// add Msg
+			if est == 3 {
+				f.Msg = "FUPACE with invalid date format: '" + date + "'"
+			} else if m["FUPACE"] == "1" {
+				f.Msg = "FUPACE with no date but code is 1."
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-					// if no duplicates, store in a slice
-					if !sbe3.CompareEvents(allSBE) {
-						allSBE = append(allSBE, sbe3)
-					}
-					// if date is empty or has invalid format, create a fix event
-				} else if (est == 2 && m["SBE3"] == "1") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					f.Source.Path = append(f.Source.Path, path)
-					// check for Organism
-					if ORGANISM != "" {
-						organism = ORGANISM
-					}
-					// add Msg
-					if est == 3 {
-						f.Msg = "SBE with invalid date format: '" + date + "',  organism: '" + organism + "'"
-					} else if m["SBE3"] == "1" {
-						f.Msg = "SBE with no date but code is 1, organism: '" + organism + "'"
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-				}
+		// Event SBE
+		// estimate date's value and format
+		date, est = helper.CheckDateFormat(e, path, j, i, "SBE1_Date", m["SBE1_D"])
+		// get value for Organism
+		ORGANISM := m["SBE1 ORGANISM"]
+		organism := m["SBE1 organism"]
+		// if date has valid format, create a sbe event
+		if est == 0 || est == 1 {
+			sbe1 := general{
+				PTID:    ID1,
+				Type:    "sbe",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "SBE1_D", "SBE1", "SBE1 ORGANISM", "SBE1 organism")}
+
+			// assign value to Organism
+			// some sheets may have organism instead of ORGANISM,
+			// the event gets its own copy as both are read again for the next sbe
+			value := organism
+			if ORGANISM != "" {
+				value = ORGANISM
+			}
+			sbe1.Organism = &value
 
-				// Event SVD
-				// estimate date value and format
-				date, est = helper.CheckDateFormat(e, path, j, i, "SVD_Date", m["SVD_D"])
-				// if date has valid format, create a struct_valve_det event
-				if est == 0 || est == 1 {
-					svd := general{
-						PTID:    ID1,
-						Type:    "struct_valve_det",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// Source: add path
-					svd.Source.Path = append(svd.Source.Path, path)
-					// if no duplicates, store in a slice
-					if !svd.CompareEvents(allSVD) {
-						allSVD = append(allSVD, svd)
-					}
-					// if date is empty or has invalid format, create a fix event
-				} else if (est == 2 && m["SVD"] == "1") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					f.Source.Path = append(f.Source.Path, path)
-
-					// add Msg
-					if est == 3 {
-						f.Msg = "SVD with invalid date format: '" + date + "'"
-					} else if m["SVD"] == "1" {
-						f.Msg = "SVD with no date but code is 1."
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-				}
+			// 	Check Organism, look it up in the organism table
+			if *sbe1.Organism == "" {
+				sbe1.Organism = nil
+			}
+			sbe1.normalizeOrganism()
 
-				// Event PVL
-				// estimate date value and format
-				date, est = helper.CheckDateFormat(e, path, j, i, "PVL1_Date", m["PVL1_D"])
-				// if date has valid format, create a perivalvular_leak event
-				if est == 0 || est == 1 {
-					pvl1 := general{
-						PTID:    ID1,
-						Type:    "perivalvular_leak",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					pvl1.Source.Path = append(pvl1.Source.Path, path)
+			// if no duplicates, store in a slice
+			if !sbe1.CompareEvents(allSBE) {
+				allSBE = append(allSBE, sbe1)
+			}
+			// if date is empty or has invalid format, create a fix event
+		} else if (est == 2 && m["SBE1"] == "1") || est == 3 {
+			f := newFix(ID1, "sbe", fixReason(est), r, m, "SBE1_D", "SBE1", "SBE1 ORGANISM", "SBE1 organism")
+			// check for Organism
+			if ORGANISM != "" {
+				organism = ORGANISM
+			}
+			// add Msg
+			if est == 3 {
+				f.Msg = "SBE with invalid date format: '" + date + "', organism: '" + organism + "'"
+			} else if m["SBE1"] == "1" {
+				f.Msg = "SBE with no date but code is 1, code: '" + m["SBE1"] + "', organism: '" + organism + "'"
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-					// if no duplicates, store in a slice
-					if !pvl1.CompareEvents(allPVL) {
-						allPVL = append(allPVL, pvl1)
-					}
-					// if date is empty or has invalid format, create a fix event
-				} else if (est == 2 && m["PVL1"] == "1") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					f.Source.Path = append(f.Source.Path, path)
-					// add Msg
-					if est == 3 {
-						f.Msg = "PVL with invalid date format: '" + date + "'"
-					} else if m["PVL1"] == "1" {
-						f.Msg = "PVL with no date but code is 1."
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-				}
+		// SBE2
+		// estimate date value and format
+		date, est = helper.CheckDateFormat(e, path, j, i, "SBE2_Date", m["SBE2_D"])
+		// get value for Organism
+		ORGANISM = m["SBE2 ORGANISM"]
+		organism = m["SBE2 organism"]
+		// if date has valid format, create a sbe event
+		if est == 0 || est == 1 {
+			sbe2 := general{
+				PTID:    ID1,
+				Type:    "sbe",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "SBE2_D", "SBE2", "SBE2 ORGANISM", "SBE2 organism")}
+
+			// assign value to Organism
+			// some sheets may have organism instead of ORGANISM,
+			// the event gets its own copy as both are read again for the next sbe
+			value := organism
+			if ORGANISM != "" {
+				value = ORGANISM
+			}
+			sbe2.Organism = &value
 
-				// PVL2
-				// estimate date value and format
-				date, est = helper.CheckDateFormat(e, path, j, i, "PVL2_Date", m["PVL2_D"])
-				// if date has valid format, create a perivalvular_leak event
-				if est == 0 || est == 1 {
-					pvl2 := general{
-						PTID:    ID1,
-						Type:    "perivalvular_leak",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
+			// 	Check Organism, look it up in the organism table
+			if *sbe2.Organism == "" {
+				sbe2.Organism = nil
+			}
+			sbe2.normalizeOrganism()
 
-					// add path to the source
-					pvl2.Source.Path = append(pvl2.Source.Path, path)
-					// if no duplicates, store in a slice
-					if !pvl2.CompareEvents(allPVL) {
-						allPVL = append(allPVL, pvl2)
-					}
-					// if date is empty or has invalid format, create a fix event
-				} else if (est == 2 && m["PVL2"] == "1") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					f.Source.Path = append(f.Source.Path, path)
-					// add Msg
-					if est == 3 {
-						f.Msg = "PVL with invalid date format: '" + date + "'"
-					} else if m["PVL2"] == "1" {
-						f.Msg = "PVL with no date but code is 1."
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-				}
+			// if no duplicates, store in a slice
+			if !sbe2.CompareEvents(allSBE) {
+				allSBE = append(allSBE, sbe2)
+			}
+			// if date is empty or has invalid format, create a fix event
+		} else if (est == 2 && m["SBE2"] == "1") || est == 3 {
+			f := newFix(ID1, "sbe", fixReason(est), r, m, "SBE2_D", "SBE2", "SBE2 ORGANISM", "SBE2 organism")
+			// check for Organism
+			if ORGANISM != "" {
+				organism = ORGANISM
+			}
+			// add Msg
+			if est == 3 {
+				f.Msg = "SBE with invalid date format: '" + date + "', organism: '" + organism + "'"
+			} else if m["SBE2"] == "1" {
+				f.Msg = "SBE with no date but code is 1, organism: '" + organism + "'"
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-				// Event DVT
-				// estimate value and format of the date
-				date, est = helper.CheckDateFormat(e, path, j, i, "DVT_Date", m["DVT_D"])
-				// if date has valid format, create a deep_vein_thrombosis event
-				if est == 0 || est == 1 {
-					dvt := general{
-						PTID:    ID1,
-						Type:    "deep_vein_thrombosis",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to source
-					dvt.Source.Path = append(dvt.Source.Path, path)
-					// if no duplicates, store in a slice
-					if !dvt.CompareEvents(allDVT) {
-						allDVT = append(allDVT, dvt)
-					}
-					// if date is empty or has invalid format, create a fix event
-				} else if (est == 2 && m["DVT"] == "1") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					f.Source.Path = append(f.Source.Path, path)
-					// add Msg
-					if est == 3 {
-						f.Msg = "DVT with invalid date format: '" + date + "'"
-					} else if m["DVT"] == "1" {
-						f.Msg = "DVT with no date but code is 1."
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-				}
+		// SBE3
+		// estimate date value and format
+		date, est = helper.CheckDateFormat(e, path, j, i, "SBE3_Date", m["SBE3_D"])
+		// get value for Organism
+		ORGANISM = m["SBE3 ORGANISM"]
+		organism = m["SBE3 organism"]
+		// if date has valid format, create a sbe event
+		if est == 0 || est == 1 {
+			sbe3 := general{
+				PTID:    ID1,
+				Type:    "sbe",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "SBE3_D", "SBE3", "SBE3 ORGANISM", "SBE3 organism")}
+
+			// assign value to Organism
+			// some sheets may have organism instead of ORGANISM,
+			// the event gets its own copy as both are read again for the next sbe
+			value := organism
+			if ORGANISM != "" {
+				value = ORGANISM
+			}
+			sbe3.Organism = &value
 
-				// Event ARH
-				// estimate the value and format
-				date, est = helper.CheckDateFormat(e, path, j, i, "ARH1_Date", m["ARH1_D"])
-				// if date has valid format, create an arh event
-				if est == 0 || est == 1 {
-					arh1 := general{
-						PTID:    ID1,
-						Type:    "arh",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					arh1.Source.Path = append(arh1.Source.Path, path)
-
-					// validate arh code
-					if !helper.CheckIntValue(&arh1.Code, m["ARH1"], nums[:]) {
-						msg := errMessage{"code", "invalid value: '" + m["ARH1"] + "'"}
-						arh1.Fix = append(arh1.Fix, msg)
-					}
-					// if no duplicates, store in a slice
-					if !arh1.CompareEvents(allARH) {
-						allARH = append(allARH, arh1)
-					}
-					// if date has invalid format or is empty but other fields have values,
-					// create a fix event
-				} else if (est == 2 && m["ARH1"] != "0" && m["ARH1"] != "") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					f.Source.Path = append(f.Source.Path, path)
-					// add Msg
-					if est == 3 {
-						f.Msg = "ARH with invalid date format: '" + date + "', " + helper.ArhCode(m["ARH1"])
-					} else if m["ARH1"] != "0" && m["ARH1"] != "" {
-						f.Msg = "ARH with no date but code is not 0 or empty, " + helper.ArhCode(m["ARH1"])
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-				}
+			// 	Check Organism, look it up in the organism table
+			if *sbe3.Organism == "" {
+				sbe3.Organism = nil
+			}
+			sbe3.normalizeOrganism()
 
-				// ARH2
-				// estimate the format and value
-				date, est = helper.CheckDateFormat(e, path, j, i, "ARH2_Date", m["ARH2_D"])
-				// if date has valid format, create an arh event
-				if est == 0 || est == 1 {
-					arh2 := general{
-						PTID:    ID1,
-						Type:    "arh",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					arh2.Source.Path = append(arh2.Source.Path, path)
-
-					// validate arh code
-					if !helper.CheckIntValue(&arh2.Code, m["ARH2"], nums[:]) {
-						msg := errMessage{"code", "invalid value: '" + m["ARH2"] + "'"}
-						arh2.Fix = append(arh2.Fix, msg)
-					}
-					// if no duplicates, store in a slice
-					if !arh2.CompareEvents(allARH) {
-						allARH = append(allARH, arh2)
-					}
-					// if date is empty or has invalid format
-				} else if (est == 2 && m["ARH2"] != "0" && m["ARH2"] != "") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					f.Source.Path = append(f.Source.Path, path)
-					// add Msg
-					if est == 3 {
-						f.Msg = "ARH with invalid date format: '" + date + "', " + helper.ArhCode(m["ARH2"])
-					} else if m["ARH2"] != "0" && m["ARH2"] != "" {
-						f.Msg = "ARH with no date but code is not 0 or empty, " + helper.ArhCode(m["ARH2"])
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-				}
+			// if no duplicates, store in a slice
+			if !sbe3.CompareEvents(allSBE) {
+				allSBE = append(allSBE, sbe3)
+			}
+			// if date is empty or has invalid format, create a fix event
+		} else if (est == 2 && m["SBE3"] == "1") || est == 3 {
+			f := newFix(ID1, "sbe", fixReason(est), r, m, "SBE3_D", "SBE3", "SBE3 ORGANISM", "SBE3 organism")
+			// check for Organism
+			if ORGANISM != "" {
+				organism = ORGANISM
+			}
+			// add Msg
+			if est == 3 {
+				f.Msg = "SBE with invalid date format: '" + date + "',  organism: '" + organism + "'"
+			} else if m["SBE3"] == "1" {
+				f.Msg = "SBE with no date but code is 1, organism: '" + organism + "'"
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-				// Event THRM
-				// estimate the value and format
-				date, est = helper.CheckDateFormat(e, path, j, i, "THRM1_Date", m["THRM1_D"])
-				// if date has valid format, create a thromb_prost_valve event
-				if est == 0 || est == 1 {
-					thrm1 := general{
-						PTID:    ID1,
-						Type:    "thromb_prost_valve",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
+		// Event SVD
+		// estimate date value and format
+		date, est = helper.CheckDateFormat(e, path, j, i, "SVD_Date", m["SVD_D"])
+		// if date has valid format, create a struct_valve_det event
+		if est == 0 || est == 1 {
+			svd := general{
+				PTID:    ID1,
+				Type:    "struct_valve_det",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "SVD_D", "SVD")}
+			// if no duplicates, store in a slice
+			if !svd.CompareEvents(allSVD) {
+				allSVD = append(allSVD, svd)
+			}
+			// if date is empty or has invalid format, create a fix event
+		} else if (est == 2 && m["SVD"] == "1") || est == 3 {
+			f := newFix(ID1, "struct_valve_det", fixReason(est), r, m, "SVD_D", "SVD")
+
+			// add Msg
+			if est == 3 {
+				f.Msg = "SVD with invalid date format: '" + date + "'"
+			} else if m["SVD"] == "1" {
+				f.Msg = "SVD with no date but code is 1."
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-					// add path to the source
-					thrm1.Source.Path = append(thrm1.Source.Path, path)
+		// Event PVL
+		// estimate date value and format
+		date, est = helper.CheckDateFormat(e, path, j, i, "PVL1_Date", m["PVL1_D"])
+		// if date has valid format, create a perivalvular_leak event
+		if est == 0 || est == 1 {
+			pvl1 := general{
+				PTID:    ID1,
+				Type:    "perivalvular_leak",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "PVL1_D", "PVL1")}
+
+			// if no duplicates, store in a slice
+			if !pvl1.CompareEvents(allPVL) {
+				allPVL = append(allPVL, pvl1)
+			}
+			// if date is empty or has invalid format, create a fix event
+		} else if (est == 2 && m["PVL1"] == "1") || est == 3 {
+			f := newFix(ID1, "perivalvular_leak", fixReason(est), r, m, "PVL1_D", "PVL1")
+			// add Msg
+			if est == 3 {
+				f.Msg = "PVL with invalid date format: '" + date + "'"
+			} else if m["PVL1"] == "1" {
+				f.Msg = "PVL with no date but code is 1."
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-					// if no duplicates, store in a slice
-					if !thrm1.CompareEvents(allTHRM) {
-						allTHRM = append(allTHRM, thrm1)
-					}
-					// if date has invalid format or is empty, create a fix event
-				} else if (est == 2 && m["THRM1"] == "1") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source field
-					f.Source.Path = append(f.Source.Path, path)
-					// add Msg
-					if est == 3 {
-						f.Msg = "THRM with invalid date format: '" + date + "'"
-					} else if m["THRM1"] == "1" {
-						f.Msg = "THRM with empty date but code is 1."
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-				}
+		// PVL2
+		// estimate date value and format
+		date, est = helper.CheckDateFormat(e, path, j, i, "PVL2_Date", m["PVL2_D"])
+		// if date has valid format, create a perivalvular_leak event
+		if est == 0 || est == 1 {
+			pvl2 := general{
+				PTID:    ID1,
+				Type:    "perivalvular_leak",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "PVL2_D", "PVL2")}
+
+			// if no duplicates, store in a slice
+			if !pvl2.CompareEvents(allPVL) {
+				allPVL = append(allPVL, pvl2)
+			}
+			// if date is empty or has invalid format, create a fix event
+		} else if (est == 2 && m["PVL2"] == "1") || est == 3 {
+			f := newFix(ID1, "perivalvular_leak", fixReason(est), r, m, "PVL2_D", "PVL2")
+			// add Msg
+			if est == 3 {
+				f.Msg = "PVL with invalid date format: '" + date + "'"
+			} else if m["PVL2"] == "1" {
+				f.Msg = "PVL with no date but code is 1."
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-				// THRM2
-				// estimate the value and format
-				date, est = helper.CheckDateFormat(e, path, j, i, "THRM2_Date", m["THRM2_D"])
-				// if date has valid format, create a thromb_prost_valve event
-				if est == 0 || est == 1 {
-					thrm2 := general{
-						PTID:    ID1,
-						Type:    "thromb_prost_valve",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
+		// Event DVT
+		// estimate value and format of the date
+		date, est = helper.CheckDateFormat(e, path, j, i, "DVT_Date", m["DVT_D"])
+		// if date has valid format, create a deep_vein_thrombosis event
+		if est == 0 || est == 1 {
+			dvt := general{
+				PTID:    ID1,
+				Type:    "deep_vein_thrombosis",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "DVT_D", "DVT")}
+			// if no duplicates, store in a slice
+			if !dvt.CompareEvents(allDVT) {
+				allDVT = append(allDVT, dvt)
+			}
+			// if date is empty or has invalid format, create a fix event
+		} else if (est == 2 && m["DVT"] == "1") || est == 3 {
+			f := newFix(ID1, "deep_vein_thrombosis", fixReason(est), r, m, "DVT_D", "DVT")
+			// add Msg
+			if est == 3 {
+				f.Msg = "DVT with invalid date format: '" + date + "'"
+			} else if m["DVT"] == "1" {
+				f.Msg = "DVT with no date but code is 1."
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-					// add path to the source
-					thrm2.Source.Path = append(thrm2.Source.Path, path)
+		// Event ARH
+		// estimate the value and format
+		date, est = helper.CheckDateFormat(e, path, j, i, "ARH1_Date", m["ARH1_D"])
+		// if date has valid format, create an arh event
+		if est == 0 || est == 1 {
+			arh1 := general{
+				PTID:    ID1,
+				Type:    "arh",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "ARH1_D", "ARH1")}
+
+			// validate arh code
+			if !helper.CheckIntValue(&arh1.Code, m["ARH1"], nums[:]) {
+				msg := errMessage{"code", "invalid value: '" + m["ARH1"] + "'"}
+				arh1.Fix = append(arh1.Fix, msg)
+			}
+			// if no duplicates, store in a slice
+			if !arh1.CompareEvents(allARH) {
+				allARH = append(allARH, arh1)
+			}
+			// if date has invalid format or is empty but other fields have values,
+			// create a fix event
+		} else if (est == 2 && m["ARH1"] != "0" && m["ARH1"] != "") || est == 3 {
+			f := newFix(ID1, "arh", fixReason(est), r, m, "ARH1_D", "ARH1")
+			// add Msg
+			if est == 3 {
+				f.Msg = "ARH with invalid date format: '" + date + "', " + helper.ArhCode(m["ARH1"])
+			} else if m["ARH1"] != "0" && m["ARH1"] != "" {
+				f.Msg = "ARH with no date but code is not 0 or empty, " + helper.ArhCode(m["ARH1"])
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-					// if no duplicates, store in a slice
-					if !thrm2.CompareEvents(allTHRM) {
-						allTHRM = append(allTHRM, thrm2)
-					}
-					// if date has invalid format or is empty, create a fix event
-				} else if (est == 2 && m["THRM2"] == "1") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source field
-					f.Source.Path = append(f.Source.Path, path)
-					// add Msg
-					if est == 3 {
-						f.Msg = "THRM with invalid date format: '" + date + "'"
-					} else if m["THRM2"] == "1" {
-						f.Msg = "THRM with empty date but code is 1."
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-				}
+		// ARH2
+		// estimate the format and value
+		date, est = helper.CheckDateFormat(e, path, j, i, "ARH2_Date", m["ARH2_D"])
+		// if date has valid format, create an arh event
+		if est == 0 || est == 1 {
+			arh2 := general{
+				PTID:    ID1,
+				Type:    "arh",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "ARH2_D", "ARH2")}
+
+			// validate arh code
+			if !helper.CheckIntValue(&arh2.Code, m["ARH2"], nums[:]) {
+				msg := errMessage{"code", "invalid value: '" + m["ARH2"] + "'"}
+				arh2.Fix = append(arh2.Fix, msg)
+			}
+			// if no duplicates, store in a slice
+			if !arh2.CompareEvents(allARH) {
+				allARH = append(allARH, arh2)
+			}
+			// if date is empty or has invalid format
+		} else if (est == 2 && m["ARH2"] != "0" && m["ARH2"] != "") || est == 3 {
+			f := newFix(ID1, "arh", fixReason(est), r, m, "ARH2_D", "ARH2")
+			// add Msg
+			if est == 3 {
+				f.Msg = "ARH with invalid date format: '" + date + "', " + helper.ArhCode(m["ARH2"])
+			} else if m["ARH2"] != "0" && m["ARH2"] != "" {
+				f.Msg = "ARH with no date but code is not 0 or empty, " + helper.ArhCode(m["ARH2"])
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-				// Event HEML
-				// estimate value and format
-				date, est = helper.CheckDateFormat(e, path, j, i, "HEML1_Date", m["HEML1_D"])
-				// if date has valid format, create a hemolysis_dx event
-				if est == 0 || est == 1 {
-					heml1 := general{
-						PTID:    ID1,
-						Type:    "hemolysis_dx",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					heml1.Source.Path = append(heml1.Source.Path, path)
+		// Event THRM
+		// estimate the value and format
+		date, est = helper.CheckDateFormat(e, path, j, i, "THRM1_Date", m["THRM1_D"])
+		// if date has valid format, create a thromb_prost_valve event
+		if est == 0 || est == 1 {
+			thrm1 := general{
+				PTID:    ID1,
+				Type:    "thromb_prost_valve",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "THRM1_D", "THRM1")}
+
+			// if no duplicates, store in a slice
+			if !thrm1.CompareEvents(allTHRM) {
+				allTHRM = append(allTHRM, thrm1)
+			}
+			// if date has invalid format or is empty, create a fix event
+		} else if (est == 2 && m["THRM1"] == "1") || est == 3 {
+			f := newFix(ID1, "thromb_prost_valve", fixReason(est), r, m, "THRM1_D", "THRM1")
+			// add Msg
+			if est == 3 {
+				f.Msg = "THRM with invalid date format: '" + date + "'"
+			} else if m["THRM1"] == "1" {
+				f.Msg = "THRM with empty date but code is 1."
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-					// if no duplicates, store in a slice
-					if !heml1.CompareEvents(alllHEML) {
-						alllHEML = append(alllHEML, heml1)
-					}
-					// if date is empty or has invalid format, create a fix event
-				} else if (est == 2 && m["HEML1"] == "1") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					f.Source.Path = append(f.Source.Path, path)
-					// add Msg
-					if est == 3 {
-						f.Msg = "HEML with invalid date format: '" + date + "'"
-					} else if m["HEML1"] == "1" {
-						f.Msg = "HEML with empty date but code is 1."
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-				}
+		// THRM2
+		// estimate the value and format
+		date, est = helper.CheckDateFormat(e, path, j, i, "THRM2_Date", m["THRM2_D"])
+		// if date has valid format, create a thromb_prost_valve event
+		if est == 0 || est == 1 {
+			thrm2 := general{
+				PTID:    ID1,
+				Type:    "thromb_prost_valve",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "THRM2_D", "THRM2")}
+
+			// if no duplicates, store in a slice
+			if !thrm2.CompareEvents(allTHRM) {
+				allTHRM = append(allTHRM, thrm2)
+			}
+			// if date has invalid format or is empty, create a fix event
+		} else if (est == 2 && m["THRM2"] == "1") || est == 3 {
+			f := newFix(ID1, "thromb_prost_valve", fixReason(est), r, m, "THRM2_D", "THRM2")
+			// add Msg
+			if est == 3 {
+				f.Msg = "THRM with invalid date format: '" + date + "'"
+			} else if m["THRM2"] == "1" {
+				f.Msg = "THRM with empty date but code is 1."
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-				//HEML2
+		// Event HEML
+		// estimate value and format
+		date, est = helper.CheckDateFormat(e, path, j, i, "HEML1_Date", m["HEML1_D"])
+		// if date has valid format, create a hemolysis_dx event
+		if est == 0 || est == 1 {
+			heml1 := general{
+				PTID:    ID1,
+				Type:    "hemolysis_dx",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "HEML1_D", "HEML1")}
+
+			// if no duplicates, store in a slice
+			if !heml1.CompareEvents(alllHEML) {
+				alllHEML = append(alllHEML, heml1)
+			}
+			// if date is empty or has invalid format, create a fix event
+		} else if (est == 2 && m["HEML1"] == "1") || est == 3 {
+			f := newFix(ID1, "hemolysis_dx", fixReason(est), r, m, "HEML1_D", "HEML1")
+			// add Msg
+			if est == 3 {
+				f.Msg = "HEML with invalid date format: '" + date + "'"
+			} else if m["HEML1"] == "1" {
+				f.Msg = "HEML with empty date but code is 1."
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
+			}
+		}
 
-				// estimate value and format of the date
-				date, est = helper.CheckDateFormat(e, path, j, i, "HEML2_Date", m["HEML2_D"])
-				// if date has a valid format, create a hemolysis_dx event
-				if est == 0 || est == 1 {
-					heml2 := general{
-						PTID:    ID1,
-						Type:    "hemolysis_dx",
-						Date:    date,
-						DateEst: est,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					heml2.Source.Path = append(heml2.Source.Path, path)
-					// if no duplicates, store in a slice
-					if !heml2.CompareEvents(alllHEML) {
-						alllHEML = append(alllHEML, heml2)
-					}
-					// if date is empty or has invalid format, create a fix event
-				} else if (est == 2 && m["HEML2"] == "1") || est == 3 {
-					f := general{
-						PTID:    ID1,
-						Type:    "fix",
-						Date:    "1900-01-01",
-						DateEst: 1,
-						Source:  source{Type: "followup", Path: []string{}}}
-					// add path to the source
-					f.Source.Path = append(f.Source.Path, path)
-					// add Msg
-					if est == 3 {
-						f.Msg = "HEML with invalid date format: '" + date + "'"
-					} else if m["HEML2"] == "1" {
-						f.Msg = "HEML with empty date but code is 1."
-					}
-					// if no duplicates, store in a slice
-					if !f.CompareEvents(allFix) {
-						allFix = append(allFix, f)
-					}
-				}
-				//	}
+		//HEML2
+
+		// estimate value and format of the date
+		date, est = helper.CheckDateFormat(e, path, j, i, "HEML2_Date", m["HEML2_D"])
+		// if date has a valid format, create a hemolysis_dx event
+		if est == 0 || est == 1 {
+			heml2 := general{
+				PTID:    ID1,
+				Type:    "hemolysis_dx",
+				Date:    date,
+				DateEst: est,
+				Source:  newSource(r, m, "HEML2_D", "HEML2")}
+			// if no duplicates, store in a slice
+			if !heml2.CompareEvents(alllHEML) {
+				alllHEML = append(alllHEML, heml2)
+			}
+			// if date is empty or has invalid format, create a fix event
+		} else if (est == 2 && m["HEML2"] == "1") || est == 3 {
+			f := newFix(ID1, "hemolysis_dx", fixReason(est), r, m, "HEML2_D", "HEML2")
+			// add Msg
+			if est == 3 {
+				f.Msg = "HEML with invalid date format: '" + date + "'"
+			} else if m["HEML2"] == "1" {
+				f.Msg = "HEML with empty date but code is 1."
+			}
+			// if no duplicates, store in a slice
+			if !f.CompareFix(allFix) {
+				allFix = append(allFix, f)
 			}
 		}
+		//	}
 	}
 }
@@ -0,0 +1,225 @@
+package excel2json
+
+import (
+	"encoding/csv"
+	"excel/helper"
+	"excel/stats"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// the valve-morbidity categories of the report and the types of the events of each one
+var rateCategories = []struct {
+	Name  string
+	Types []string
+}{
+	{"structural valve deterioration", []string{"struct_valve_det"}},
+	{"valve thrombosis", []string{"thromb_prost_valve"}},
+	{"embolism", []string{"stroke", "tia"}},
+	{"bleeding", []string{"arh"}},
+	{"endocarditis", []string{"sbe"}},
+	{"perivalvular leak", []string{"perivalvular_leak"}},
+	{"reoperation", []string{"operation"}},
+	{"death", []string{"death"}},
+}
+
+// the rate of the events of a category in a group of patients
+type rateRow struct {
+	Group        string // all, or the year of the index operations
+	Patients     int
+	PatientYears float64
+	Category     string
+	Events       int
+	Rate         float64 // events per 100 patient-years
+	Lower        float64
+	Upper        float64
+}
+
+// loadOutput reads the events of a JSON file written by WriteToJSON into the slices.
+func loadOutput(e *log.Logger, path string) {
+	ev := events{}
+	for _, o := range readOutput(e, path) {
+		switch v := o.Value.(type) {
+		case followups:
+			if v.Type == "last_known_alive" {
+				ev.LKA = append(ev.LKA, v)
+			} else {
+				ev.FollowUps = append(ev.FollowUps, v)
+			}
+		case death:
+			ev.Dths = append(ev.Dths, v)
+		case te:
+			if v.Type == "tia" {
+				ev.TIA = append(ev.TIA, v)
+			} else {
+				ev.Stroke = append(ev.Stroke, v)
+			}
+		case lostFollowup:
+			ev.LostFollowups = append(ev.LostFollowups, v)
+		case operation:
+			ev.Operation = append(ev.Operation, v)
+		case fix:
+			ev.Fix = append(ev.Fix, v)
+		case general:
+			switch v.Type {
+			case "sbe":
+				ev.SBE = append(ev.SBE, v)
+			case "arh":
+				ev.ARH = append(ev.ARH, v)
+			case "myocardial_infarction":
+				ev.FUMI = append(ev.FUMI, v)
+			case "perm_pacemaker":
+				ev.FUPACE = append(ev.FUPACE, v)
+			case "struct_valve_det":
+				ev.SVD = append(ev.SVD, v)
+			case "perivalvular_leak":
+				ev.PVL = append(ev.PVL, v)
+			case "deep_vein_thrombosis":
+				ev.DVT = append(ev.DVT, v)
+			case "thromb_prost_valve":
+				ev.THRM = append(ev.THRM, v)
+			case "hemolysis_dx":
+				ev.HEML = append(ev.HEML, v)
+			}
+		}
+	}
+	putEvents(ev)
+	linkOperations()
+}
+
+// eventRates returns the linearized rates of each category for all patients and for the patients
+// of each year of index operation. The follow-up of a patient is the one of the survival dataset,
+// and every event in it counts, not only the first one. level is the confidence level.
+func eventRates(e *log.Logger, level float64) []rateRow {
+	rows := survivalRows(e)
+	byID := map[string]survivalRow{}
+	for _, r := range rows {
+		byID[r.PTID] = r
+	}
+	// the number of events of each type of each patient during the follow-up
+	counts := map[string]map[string]int{}
+	for _, ev := range allEvents() {
+		r, ok := byID[*ev.PTID]
+		if !ok || *ev.Date < r.IndexDate || *ev.Date > r.EndDate {
+			continue
+		}
+		if ev.Type == "operation" && *ev.Date == r.IndexDate {
+			continue
+		}
+		if counts[r.PTID] == nil {
+			counts[r.PTID] = map[string]int{}
+		}
+		counts[r.PTID][ev.Type]++
+	}
+
+	// the groups, all patients first and then the years in order
+	groups := []string{"all"}
+	members := map[string][]survivalRow{}
+	for _, r := range rows {
+		year := r.IndexDate[:4]
+		if members[year] == nil {
+			groups = append(groups, year)
+		}
+		members["all"] = append(members["all"], r)
+		members[year] = append(members[year], r)
+	}
+	sort.Strings(groups[1:])
+
+	list := []rateRow{}
+	for _, g := range groups {
+		years := 0.0
+		for _, r := range members[g] {
+			years += float64(r.FollowupDays) / 365.25
+		}
+		for _, c := range rateCategories {
+			n := 0
+			for _, r := range members[g] {
+				for _, t := range c.Types {
+					// a patient dies once
+					if t == "death" {
+						n += r.Death
+					} else {
+						n += counts[r.PTID][t]
+					}
+				}
+			}
+			rate, lower, upper := stats.LinearizedRate(n, years, level)
+			list = append(list, rateRow{g, len(members[g]), years, c.Name, n, rate, lower, upper})
+		}
+	}
+	return list
+}
+
+// writeRatesText writes the rates as a table for each group.
+func writeRatesText(w io.Writer, list []rateRow, level float64) {
+	for i, r := range list {
+		if i == 0 || list[i-1].Group != r.Group {
+			if r.Group == "all" {
+				fmt.Fprintf(w, "All patients: %d patients, %.1f patient-years\n", r.Patients, r.PatientYears)
+			} else {
+				fmt.Fprintf(w, "\nOperation year %s: %d patients, %.1f patient-years\n", r.Group, r.Patients, r.PatientYears)
+			}
+			fmt.Fprintf(w, "  %-32s %7s %12s   %g%% CI\n", "category", "events", "/100 pt-yr", level*100)
+		}
+		fmt.Fprintf(w, "  %-32s %7d %12.2f   %.2f - %.2f\n", r.Category, r.Events, r.Rate, r.Lower, r.Upper)
+	}
+}
+
+// writeRatesCSV writes the rates to a CSV file, one row per group and category.
+func writeRatesCSV(path string, list []rateRow) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+	w.Write([]string{"group", "patients", "patient_years", "category", "events", "rate_per_100_patient_years", "lower", "upper"})
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+	for _, r := range list {
+		w.Write([]string{r.Group, strconv.Itoa(r.Patients), f(r.PatientYears), r.Category,
+			strconv.Itoa(r.Events), f(r.Rate), f(r.Lower), f(r.Upper)})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// ReportRates reads the events of a JSON file written by WriteToJSON and writes to w the
+// linearized occurrence rates per 100 patient-years of the valve-morbidity categories, with
+// exact Poisson confidence intervals of the level, for all patients and by operation year.
+// The rates are also written to the CSV file of csvPath if it is not empty.
+// Returns the number of patients without an operation, who are left out.
+func ReportRates(e *log.Logger, path string, level float64, w io.Writer, csvPath string) int {
+	loadOutput(e, path)
+	list := eventRates(e, level)
+	writeRatesText(w, list, level)
+	if csvPath != "" {
+		helper.CheckErr(e, writeRatesCSV(csvPath, list))
+	}
+	return leftOut(e, path)
+}
+
+// leftOut writes the patients of the events of the JSON file of path that have no operation,
+// and so no follow-up, to the errlog, and returns their number.
+func leftOut(e *log.Logger, path string) int {
+	left := map[string]bool{}
+	for _, ev := range allEvents() {
+		left[*ev.PTID] = true
+	}
+	for _, o := range allOperation {
+		delete(left, o.PTID)
+	}
+	delete(left, "")
+	ids := []string{}
+	for id := range left {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		e.Println(path, "INFO: patient without an operation is left out of the report:", id)
+	}
+	return len(ids)
+}
@@ -0,0 +1,445 @@
+package excel2json
+
+import (
+	"encoding/json"
+	"excel/helper"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// a rule of the rules file, a check of the cells of a row written as an expression
+type rule struct {
+	Name     string `json:"name"`
+	Expr     string `json:"expr"`     // true when the row breaks the rule, e.g. DIED == 1 && empty(DTH_D)
+	Severity string `json:"severity"` // error for a fix event, warning for the error log only
+	Message  string `json:"message"`  // {COLUMN} is replaced by the value of the column
+	Event    string `json:"event"`    // the event type of the fix events, row if empty
+	Column   string `json:"column"`   // the column that needs fixing, the first column of expr if empty
+	Sheet    string `json:"sheet"`    // the type of the sheets it checks, followup if empty
+	node     *node
+	columns  []string
+}
+
+// the rules of the rules file that the rows are checked with
+var rules []rule
+
+// loadRules reads the rules from the JSON file of opts.Rules, a list of objects with the
+// name, the expression, the severity and the message of a rule, e.g.
+// [{"name": "death date", "expr": "DIED == 1 && empty(DTH_D)", "severity": "error", "message": "DIED is 1 without DTH_D"}]
+// A rule that cannot be parsed stops the run.
+func loadRules(e *log.Logger) {
+	rules = nil
+	if opts.Rules == "" {
+		return
+	}
+	file, err := os.Open(opts.Rules)
+	helper.CheckErr(e, err)
+	defer file.Close()
+	helper.CheckErr(e, json.NewDecoder(file).Decode(&rules))
+	for i := range rules {
+		r := &rules[i]
+		if r.Severity == "" {
+			r.Severity = "error"
+		} else if r.Severity != "error" && r.Severity != "warning" {
+			helper.CheckErr(e, fmt.Errorf("rule '%s': invalid severity: '%s', it is error or warning", r.Name, r.Severity))
+		}
+		if r.Event == "" {
+			r.Event = "row"
+		}
+		if r.Sheet == "" {
+			r.Sheet = "followup"
+		}
+		p := &parser{src: r.Expr}
+		r.node, err = p.parse()
+		if err == nil && r.node.typ != boolType {
+			err = fmt.Errorf("it is not true or false")
+		}
+		if err != nil {
+			helper.CheckErr(e, fmt.Errorf("rule '%s': invalid expression: '%s': %v", r.Name, r.Expr, err))
+		}
+		r.columns = p.columns
+		if r.Column == "" && len(r.columns) > 0 {
+			r.Column = r.columns[0]
+		}
+	}
+}
+
+// checkRules checks the row m of a sheet of type sheetType against the rules. A rule only
+// checks the sheets that have all its columns. A broken rule of severity error creates a fix
+// event of the patient id, one of severity warning is written to the error log.
+// path is the sub path of the excel file, j the index of the sheet, i the index of the row
+// and keys its header row, r is the row that the events of this row come from.
+func checkRules(e *log.Logger, path string, sheetType string, j int, i int, keys []string, m map[string]string, id string, r rowRef) {
+	env := &rowEnv{e: e, path: path, j: j, i: i, m: m}
+	for _, rl := range rules {
+		if rl.Sheet != sheetType || !hasColumns(keys, rl.columns) || !rl.node.eval(env).b {
+			continue
+		}
+		msg := "rule '" + rl.Name + "': " + env.expand(rl.Message)
+		if rl.Severity == "warning" {
+			e.Println(path, "Sheet #:", j+1, "Row #:", i+2, "WARNING:", msg)
+			continue
+		}
+		columns := append([]string{rl.Column}, rl.columns...)
+		f := newFix(id, rl.Event, fixRule, r, m, columns...)
+		f.Msg = msg
+		// if no duplicates, store in a slice
+		if !f.CompareFix(allFix) {
+			allFix = append(allFix, f)
+		}
+	}
+}
+
+// hasColumns returns true if the header row keys has all the columns.
+func hasColumns(keys []string, columns []string) bool {
+	header := map[string]bool{}
+	for _, k := range keys {
+		header[k] = true
+	}
+	for _, c := range columns {
+		if !header[c] {
+			return false
+		}
+	}
+	return true
+}
+
+// the row that a rule is checked against, and where it is for the error log
+type rowEnv struct {
+	e    *log.Logger
+	path string
+	j, i int
+	m    map[string]string
+}
+
+// the columns in braces of a message
+var messageColumn = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// expand replaces the columns in braces of a message by their values.
+func (r *rowEnv) expand(msg string) string {
+	return messageColumn.ReplaceAllStringFunc(msg, func(s string) string {
+		return strings.TrimSpace(r.m[s[1:len(s)-1]])
+	})
+}
+
+// the types of the values of the expressions
+const (
+	stringType = iota
+	boolType
+)
+
+// the value of an expression, a string or true or false
+type value struct {
+	s string
+	b bool
+}
+
+// a node of a parsed expression
+type node struct {
+	op   string // col, lit, a function name or an operator
+	s    string // the column name or the literal
+	args []*node
+	typ  int
+	reg  *regexp.Regexp // the literal pattern of matches
+}
+
+// the functions of the expressions, and the types of their arguments and results
+var ruleFunctions = map[string]struct {
+	args []int
+	typ  int
+}{
+	"empty":   {[]int{stringType}, boolType},               // empty(x): x is empty
+	"date":    {[]int{stringType}, stringType},             // date(x): x as YYYY-MM-DD, empty if it is not a date
+	"days":    {[]int{stringType, stringType}, stringType}, // days(x, y): the days from the date x to the date y
+	"lower":   {[]int{stringType}, stringType},             // lower(x): x in lower case
+	"matches": {[]int{stringType, stringType}, boolType},   // matches(x, 'regexp'): x matches the regular expression
+}
+
+// eval returns the value of the expression for the row.
+func (n *node) eval(r *rowEnv) value {
+	switch n.op {
+	case "col":
+		return value{s: strings.TrimSpace(r.m[n.s])}
+	case "lit":
+		return value{s: n.s}
+	case "!":
+		return value{b: !n.args[0].eval(r).b}
+	case "&&":
+		return value{b: n.args[0].eval(r).b && n.args[1].eval(r).b}
+	case "||":
+		return value{b: n.args[0].eval(r).b || n.args[1].eval(r).b}
+	case "==", "!=", "<", "<=", ">", ">=":
+		return value{b: compare(n.op, n.args[0].eval(r).s, n.args[1].eval(r).s)}
+	case "empty":
+		return value{b: n.args[0].eval(r).s == ""}
+	case "date":
+		return value{s: r.date(n.args[0].eval(r).s)}
+	case "days":
+		a, b := r.date(n.args[0].eval(r).s), r.date(n.args[1].eval(r).s)
+		if a == "" || b == "" {
+			return value{}
+		}
+		return value{s: strconv.Itoa(daysBetween(a, b))}
+	case "lower":
+		return value{s: strings.ToLower(n.args[0].eval(r).s)}
+	case "matches":
+		return value{b: n.reg.MatchString(n.args[0].eval(r).s)}
+	}
+	return value{}
+}
+
+// date returns s as YYYY-MM-DD, or empty if it is not a date.
+func (r *rowEnv) date(s string) string {
+	d, est := helper.CheckDateFormat(r.e, r.path, r.j, r.i, "rule", s)
+	if est == 0 || est == 1 {
+		// pad the month and the day, so that the dates compare as strings
+		if t, err := time.Parse("2006-1-2", d); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return ""
+}
+
+// compare compares two values with the operator, as numbers if both are numbers,
+// else as strings. A number and an empty value or a text are not comparable, e.g. the days
+// of an empty date: they are different, and the other comparisons are false.
+func compare(op string, a string, b string) bool {
+	c := strings.Compare(a, b)
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if (errA == nil) != (errB == nil) {
+		return op == "!="
+	}
+	if errA == nil && errB == nil {
+		c = 0
+		if x < y {
+			c = -1
+		} else if x > y {
+			c = 1
+		}
+	}
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+// parser parses an expression of a rule:
+//
+//	or   = and { "||" and }
+//	and  = not { "&&" not }
+//	not  = "!" not | cmp
+//	cmp  = term [ ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) term ]
+//	term = number | 'string' | "string" | COLUMN | [COLUMN NAME] | function "(" or { "," or } ")" | "(" or ")"
+type parser struct {
+	src     string
+	pos     int
+	columns []string // the columns of the expression, in order
+}
+
+// parse parses the whole expression.
+func (p *parser) parse() (*node, error) {
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.skip(); p.pos < len(p.src) {
+		return nil, fmt.Errorf("unexpected '%s' at %d", p.src[p.pos:], p.pos+1)
+	}
+	return n, nil
+}
+
+// skip skips the spaces.
+func (p *parser) skip() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+// accept skips the token tok if it is next.
+func (p *parser) accept(tok string) bool {
+	p.skip()
+	if strings.HasPrefix(p.src[p.pos:], tok) {
+		p.pos += len(tok)
+		return true
+	}
+	return false
+}
+
+// logical returns the node of a logical operator, both operands must be true or false.
+func logical(op string, args ...*node) (*node, error) {
+	for _, a := range args {
+		if a.typ != boolType {
+			return nil, fmt.Errorf("the operands of %s are not true or false", op)
+		}
+	}
+	return &node{op: op, args: args, typ: boolType}, nil
+}
+
+func (p *parser) or() (*node, error) {
+	n, err := p.and()
+	for err == nil && p.accept("||") {
+		var m *node
+		if m, err = p.and(); err == nil {
+			n, err = logical("||", n, m)
+		}
+	}
+	return n, err
+}
+
+func (p *parser) and() (*node, error) {
+	n, err := p.not()
+	for err == nil && p.accept("&&") {
+		var m *node
+		if m, err = p.not(); err == nil {
+			n, err = logical("&&", n, m)
+		}
+	}
+	return n, err
+}
+
+func (p *parser) not() (*node, error) {
+	if p.accept("!") {
+		n, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return logical("!", n)
+	}
+	return p.cmp()
+}
+
+func (p *parser) cmp() (*node, error) {
+	n, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	// the two-character operators first
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			m, err := p.term()
+			if err != nil {
+				return nil, err
+			}
+			if n.typ != stringType || m.typ != stringType {
+				return nil, fmt.Errorf("the operands of %s are not values", op)
+			}
+			return &node{op: op, args: []*node{n, m}, typ: boolType}, nil
+		}
+	}
+	return n, nil
+}
+
+func (p *parser) term() (*node, error) {
+	p.skip()
+	if p.pos == len(p.src) {
+		return nil, fmt.Errorf("unexpected end")
+	}
+	start := p.pos
+	switch c := p.src[p.pos]; {
+	case c == '(':
+		p.pos++
+		n, err := p.or()
+		if err == nil && !p.accept(")") {
+			err = fmt.Errorf("missing ) at %d", p.pos+1)
+		}
+		return n, err
+	case c == '\'' || c == '"':
+		end := strings.IndexByte(p.src[p.pos+1:], c)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string at %d", start+1)
+		}
+		p.pos += end + 2
+		return &node{op: "lit", s: p.src[start+1 : p.pos-1]}, nil
+	case c == '[':
+		end := strings.IndexByte(p.src[p.pos:], ']')
+		if end < 0 {
+			return nil, fmt.Errorf("missing ] at %d", start+1)
+		}
+		p.pos += end + 1
+		return p.column(strings.TrimSpace(p.src[start+1 : p.pos-1])), nil
+	case c == '-' || c == '.' || unicode.IsDigit(rune(c)):
+		p.pos++
+		for p.pos < len(p.src) && (p.src[p.pos] == '.' || unicode.IsDigit(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+		if _, err := strconv.ParseFloat(p.src[start:p.pos], 64); err != nil {
+			return nil, fmt.Errorf("invalid number '%s' at %d", p.src[start:p.pos], start+1)
+		}
+		return &node{op: "lit", s: p.src[start:p.pos]}, nil
+	case c == '_' || unicode.IsLetter(rune(c)):
+		for p.pos < len(p.src) && (p.src[p.pos] == '_' || unicode.IsLetter(rune(p.src[p.pos])) || unicode.IsDigit(rune(p.src[p.pos]))) {
+			p.pos++
+		}
+		name := p.src[start:p.pos]
+		if !p.accept("(") {
+			return p.column(name), nil
+		}
+		return p.call(name)
+	}
+	return nil, fmt.Errorf("unexpected '%s' at %d", p.src[start:], start+1)
+}
+
+// column returns the node of a column and adds it to the columns of the expression.
+func (p *parser) column(name string) *node {
+	for _, c := range p.columns {
+		if c == name {
+			return &node{op: "col", s: name}
+		}
+	}
+	p.columns = append(p.columns, name)
+	return &node{op: "col", s: name}
+}
+
+// call parses the arguments of a function, after its "(".
+func (p *parser) call(name string) (*node, error) {
+	f, ok := ruleFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+	n := &node{op: name, typ: f.typ}
+	for !p.accept(")") {
+		if len(n.args) > 0 && !p.accept(",") {
+			return nil, fmt.Errorf("missing , or ) at %d", p.pos+1)
+		}
+		a, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		n.args = append(n.args, a)
+	}
+	if len(n.args) != len(f.args) {
+		return nil, fmt.Errorf("wrong number of arguments of %s, it takes %d", name, len(f.args))
+	}
+	for k, a := range n.args {
+		if a.typ != f.args[k] {
+			return nil, fmt.Errorf("invalid argument %d of %s", k+1, name)
+		}
+	}
+	if name == "matches" {
+		if n.args[1].op != "lit" {
+			return nil, fmt.Errorf("the pattern of matches is not a string")
+		}
+		reg, err := regexp.Compile(n.args[1].s)
+		if err != nil {
+			return nil, err
+		}
+		n.reg = reg
+	}
+	return n, nil
+}
@@ -0,0 +1,115 @@
+package excel2json
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"excel/helper"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+)
+
+// the events and the workbook record of an excel file, from the last run
+type cacheEntry struct {
+	Hash     string   `json:"hash"` // sha256 of the content of the excel file
+	Workbook workbook `json:"workbook"`
+	Events   events   `json:"events"`
+}
+
+// state of the last run, kept between runs to only reprocess changed workbooks
+type state struct {
+	Fingerprint string                `json:"fingerprint"` // options and columns file the events were created with
+	Workbooks   map[string]cacheEntry `json:"workbooks"`   // the key is the sub path of the excel file
+}
+
+// fileHash returns the sha256 of the content of a file as a hex string.
+func fileHash(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// stateVersion is the version of the events read from a workbook,
+// increase it when the events read from the same workbook change.
+const stateVersion = 10
+
+// fingerprint returns a string that changes when the version, the options, the columns file, the
+// sheet classes file, the aliases file, the procedures file, the organisms file, the rules file,
+// the status precedence or the index operations option that decide how events are created change,
+// so the cached events cannot be reused.
+func fingerprint(columnsChecker string) string {
+	columns, _ := fileHash(columnsChecker)
+	sheets, _ := fileHash(opts.Sheets)
+	aliasFile, _ := fileHash(opts.Aliases)
+	procFile, _ := fileHash(opts.Procedures)
+	orgsFile, _ := fileHash(opts.Organisms)
+	rulesFile, _ := fileHash(opts.Rules)
+	return fmt.Sprintf("version=%d root=%s columns=%s sheets=%s aliases=%s procedures=%s organisms=%s rules=%s precedence=%s index=%t",
+		stateVersion, opts.Root, columns, sheets, aliasFile, procFile, orgsFile, rulesFile, strings.Join(precedence, ","), opts.IndexOps)
+}
+
+// readState reads the state file, it returns an empty state if the file
+// does not exist or was written with a different fingerprint.
+func readState(e *log.Logger, statePath string, fp string) state {
+	st := state{Fingerprint: fp, Workbooks: map[string]cacheEntry{}}
+	data, err := ioutil.ReadFile(statePath)
+	if os.IsNotExist(err) {
+		return st
+	}
+	helper.CheckErr(e, err)
+	old := state{}
+	helper.CheckErr(e, json.Unmarshal(data, &old))
+	if old.Fingerprint != fp {
+		e.Println(statePath, "INFO: options or columns file changed, all workbooks are read again")
+		return st
+	}
+	if old.Workbooks != nil {
+		st.Workbooks = old.Workbooks
+	}
+	return st
+}
+
+// writeState writes the state to the state file.
+func writeState(e *log.Logger, statePath string, st state) {
+	data, err := json.Marshal(st)
+	helper.CheckErr(e, err)
+	helper.CheckErr(e, ioutil.WriteFile(statePath, data, 0666))
+}
+
+// readIncremental reads the excel files of fileList, reusing the cached events of the
+// excel files that have not changed since the last run, then merges all events.
+func readIncremental(e *log.Logger, fileList []string, jsonFile *os.File, columnsChecker string) {
+	fp := fingerprint(columnsChecker)
+	old := readState(e, opts.StatePath, fp)
+	// the new state only keeps the excel files that still exist
+	st := state{Fingerprint: fp, Workbooks: map[string]cacheEntry{}}
+
+	for _, file := range fileList {
+		sub, err := helper.SubPath(file, opts.Root)
+		helper.CheckErr(e, err)
+		hash, err := fileHash(file)
+		helper.CheckErr(e, err)
+
+		entry, ok := old.Workbooks[sub]
+		if !ok || entry.Hash != hash {
+			// read the changed excel file on its own, then put back the events of the other files
+			others := takeEvents()
+			ReadExcelData(e, file, jsonFile, columnsChecker)
+			entry = cacheEntry{Hash: hash, Workbook: allWorkbooks[len(allWorkbooks)-1], Events: takeEvents()}
+			putEvents(others)
+		} else {
+			fmt.Println("unchanged, using the cached events: ", sub)
+			allWorkbooks = append(allWorkbooks, entry.Workbook)
+		}
+		// merge the events of the excel file with the events of the other files
+		entry.Events.merge()
+		st.Workbooks[sub] = entry
+	}
+	writeState(e, opts.StatePath, st)
+}
@@ -0,0 +1,103 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+// near returns true if a and b differ by at most the tolerance
+func near(a float64, b float64, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestGammaPOne(t *testing.T) {
+	t.Log("Test for GammaP - the exponential distribution")
+	p := GammaP(1, 2)
+	if !near(p, 1-math.Exp(-2), 1e-12) {
+		t.Error("Expected:", 1-math.Exp(-2), "got:", p)
+	}
+	p = GammaP(1, 0.5)
+	if !near(p, 1-math.Exp(-0.5), 1e-12) {
+		t.Error("Expected:", 1-math.Exp(-0.5), "got:", p)
+	}
+}
+
+func TestGammaQuantileOne(t *testing.T) {
+	t.Log("Test for GammaQuantile - the inverse of GammaP")
+	for _, shape := range []float64{0.5, 1, 3, 20} {
+		x := GammaQuantile(0.975, shape)
+		if p := GammaP(shape, x); !near(p, 0.975, 1e-9) {
+			t.Error("Expected:", 0.975, "got:", p, "for the shape", shape)
+		}
+	}
+}
+
+func TestPoissonCIOne(t *testing.T) {
+	t.Log("Test for PoissonCI - no events")
+	lower, upper := PoissonCI(0, 0.95)
+	if lower != 0 || !near(upper, 3.6889, 1e-4) {
+		t.Error("Expected:", 0, 3.6889, "got:", lower, upper)
+	}
+}
+
+func TestPoissonCITwo(t *testing.T) {
+	t.Log("Test for PoissonCI - the published exact limits")
+	lower, upper := PoissonCI(1, 0.95)
+	if !near(lower, 0.0253, 1e-4) || !near(upper, 5.5716, 1e-4) {
+		t.Error("Expected:", 0.0253, 5.5716, "got:", lower, upper)
+	}
+	lower, upper = PoissonCI(10, 0.95)
+	if !near(lower, 4.7954, 1e-4) || !near(upper, 18.3904, 1e-4) {
+		t.Error("Expected:", 4.7954, 18.3904, "got:", lower, upper)
+	}
+}
+
+func TestLinearizedRateOne(t *testing.T) {
+	t.Log("Test for LinearizedRate - per 100 patient-years")
+	rate, lower, upper := LinearizedRate(10, 200, 0.95)
+	if rate != 5 || !near(lower, 2.3977, 1e-4) || !near(upper, 9.1952, 1e-4) {
+		t.Error("Expected:", 5, 2.3977, 9.1952, "got:", rate, lower, upper)
+	}
+	rate, lower, upper = LinearizedRate(3, 0, 0.95)
+	if rate != 0 || lower != 0 || upper != 0 {
+		t.Error("Expected:", 0, 0, 0, "got:", rate, lower, upper)
+	}
+}
+
+func TestKaplanMeierOne(t *testing.T) {
+	t.Log("Test for KaplanMeier - the estimate and the Greenwood standard error")
+	times := []float64{1, 2, 2, 3, 4}
+	events := []bool{true, true, false, true, false}
+	points := KaplanMeier(times, events, 0.95)
+	if len(points) != 4 {
+		t.Fatal("Expected:", 4, "points, got:", len(points))
+	}
+	want := []float64{1, 0.8, 0.6, 0.3}
+	for i, p := range points {
+		if !near(p.Survival, want[i], 1e-12) {
+			t.Error("Expected:", want[i], "got:", p.Survival, "at the time", p.Time)
+		}
+	}
+	if p := points[2]; p.AtRisk != 4 || p.Censored != 1 || !near(p.StdErr, 0.21909, 1e-5) {
+		t.Error("Expected:", 4, 1, 0.21909, "got:", p.AtRisk, p.Censored, p.StdErr)
+	}
+	if p := points[2]; !(p.Lower < p.Survival && p.Survival < p.Upper && p.Lower > 0 && p.Upper < 1) {
+		t.Error("Expected a confidence interval around", p.Survival, "got:", p.Lower, p.Upper)
+	}
+}
+
+func TestSurvivalAtOne(t *testing.T) {
+	t.Log("Test for SurvivalAt - between the steps and after the follow-up")
+	times := []float64{1, 2, 2, 3, 4}
+	points := KaplanMeier(times, []bool{true, true, false, true, false}, 0.95)
+	p, ok := SurvivalAt(points, times, 2.5)
+	if !ok || p.Survival != points[2].Survival {
+		t.Error("Expected:", points[2].Survival, true, "got:", p.Survival, ok)
+	}
+	if _, ok = SurvivalAt(points, times, 5); ok {
+		t.Error("Expected:", false, "got:", ok)
+	}
+	if n := AtRisk(times, 2); n != 4 {
+		t.Error("Expected:", 4, "got:", n)
+	}
+}
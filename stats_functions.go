@@ -0,0 +1,243 @@
+// Package stats provides the statistics of the reports
+package stats
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// GammaP returns the regularized lower incomplete gamma function P(a, x) for a > 0 and x >= 0.
+func GammaP(a float64, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	lg, _ := math.Lgamma(a)
+	if x < a+1 {
+		// series expansion
+		sum, term := 1/a, 1/a
+		for n := 1; n < 1000; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if term < sum*1e-15 {
+				break
+			}
+		}
+		return sum * math.Exp(-x+a*math.Log(x)-lg)
+	}
+	// continued fraction of Q(a, x), by the modified Lentz method
+	tiny := 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < 1000; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-15 {
+			break
+		}
+	}
+	return 1 - math.Exp(-x+a*math.Log(x)-lg)*h
+}
+
+// GammaQuantile returns the x for which GammaP(shape, x) is p, the p quantile of the gamma
+// distribution with the shape and a scale of 1.
+func GammaQuantile(p float64, shape float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	// find an upper bound, then bisect
+	lo, hi := 0.0, shape+1
+	for GammaP(shape, hi) < p {
+		lo, hi = hi, hi*2
+	}
+	for i := 0; i < 200 && hi-lo > 1e-12*hi; i++ {
+		mid := (lo + hi) / 2
+		if GammaP(shape, mid) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// PoissonCI returns the exact (Garwood) confidence interval of the mean of a Poisson count k,
+// level is the confidence level, e.g. 0.95.
+func PoissonCI(k int, level float64) (float64, float64) {
+	alpha := 1 - level
+	lower := 0.0
+	if k > 0 {
+		lower = GammaQuantile(alpha/2, float64(k))
+	}
+	upper := GammaQuantile(1-alpha/2, float64(k+1))
+	return lower, upper
+}
+
+// LinearizedRate returns the linearized rate of events per 100 patient-years, and the lower and
+// upper limits of its exact Poisson confidence interval. All are 0 without patient-years.
+func LinearizedRate(events int, patientYears float64, level float64) (float64, float64, float64) {
+	if patientYears <= 0 {
+		return 0, 0, 0
+	}
+	lower, upper := PoissonCI(events, level)
+	per := 100 / patientYears
+	return float64(events) * per, lower * per, upper * per
+}
+
+// NormalQuantile returns the p quantile of the standard normal distribution.
+func NormalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// KMPoint is a step of a Kaplan-Meier estimate, the survival from the time of the step on.
+type KMPoint struct {
+	Time     float64
+	AtRisk   int // the subjects at risk just before the time
+	Events   int
+	Censored int
+	Survival float64
+	StdErr   float64 // the Greenwood standard error of the survival
+	Lower    float64
+	Upper    float64
+}
+
+// KaplanMeier returns the Kaplan-Meier estimate of the survival of subjects followed to times,
+// events[i] is true if subject i had the event at times[i] and false if censored then.
+// The first point is the time 0 with a survival of 1, then one point for each time with events.
+// The confidence intervals of the level use the Greenwood variance and the log(-log) transform,
+// so they stay between 0 and 1. Subjects censored at the time of an event are at risk at that time.
+func KaplanMeier(times []float64, events []bool, level float64) []KMPoint {
+	index := make([]int, len(times))
+	for i := range index {
+		index[i] = i
+	}
+	sort.Slice(index, func(a, b int) bool { return times[index[a]] < times[index[b]] })
+	z := NormalQuantile(1 - (1-level)/2)
+
+	points := []KMPoint{{0, len(times), 0, 0, 1, 0, 1, 1}}
+	atRisk, survival, greenwood := len(times), 1.0, 0.0
+	for i := 0; i < len(index); {
+		t := times[index[i]]
+		d, c := 0, 0
+		for ; i < len(index) && times[index[i]] == t; i++ {
+			if events[index[i]] {
+				d++
+			} else {
+				c++
+			}
+		}
+		if d > 0 {
+			survival *= float64(atRisk-d) / float64(atRisk)
+			if atRisk > d {
+				greenwood += float64(d) / float64(atRisk*(atRisk-d))
+			}
+			p := KMPoint{t, atRisk, d, c, survival, survival * math.Sqrt(greenwood), survival, survival}
+			if survival > 0 && survival < 1 {
+				se := math.Sqrt(greenwood) / math.Abs(math.Log(survival))
+				p.Lower = math.Pow(survival, math.Exp(z*se))
+				p.Upper = math.Pow(survival, math.Exp(-z*se))
+			}
+			points = append(points, p)
+		} else {
+			points[len(points)-1].Censored += c
+		}
+		atRisk -= d + c
+	}
+	return points
+}
+
+// SurvivalAt returns the point of the Kaplan-Meier estimate that holds at time t,
+// and false if t is after the last time of follow-up, where the estimate is unknown.
+func SurvivalAt(points []KMPoint, times []float64, t float64) (KMPoint, bool) {
+	last := 0.0
+	for _, v := range times {
+		last = math.Max(last, v)
+	}
+	p := points[0]
+	for _, q := range points {
+		if q.Time <= t {
+			p = q
+		}
+	}
+	return p, t <= last
+}
+
+// AtRisk returns the number of subjects still followed at time t.
+func AtRisk(times []float64, t float64) int {
+	n := 0
+	for _, v := range times {
+		if v >= t {
+			n++
+		}
+	}
+	return n
+}
+
+// PlotSVG writes an SVG plot of a Kaplan-Meier estimate, the survival in percent as a step line
+// and its confidence interval as dashed lines, with the years from 0 to maxTime on the x axis.
+func PlotSVG(w io.Writer, title string, points []KMPoint, maxTime float64) error {
+	const width, height, left, right, top, bottom = 640.0, 400.0, 60.0, 20.0, 40.0, 50.0
+	if maxTime <= 0 {
+		maxTime = 1
+	}
+	x := func(t float64) float64 { return left + math.Min(t, maxTime)/maxTime*(width-left-right) }
+	y := func(s float64) float64 { return top + (1-s)*(height-top-bottom) }
+	// step returns the path of a step line of the values of the points
+	step := func(value func(KMPoint) float64) string {
+		b := strings.Builder{}
+		fmt.Fprintf(&b, "M%.1f,%.1f", x(0), y(value(points[0])))
+		for _, p := range points[1:] {
+			if p.Time > maxTime {
+				break
+			}
+			fmt.Fprintf(&b, " H%.1f V%.1f", x(p.Time), y(value(p)))
+		}
+		fmt.Fprintf(&b, " H%.1f", x(maxTime))
+		return b.String()
+	}
+
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%g\" height=\"%g\" font-family=\"sans-serif\" font-size=\"12\">\n", width, height)
+	fmt.Fprintf(&b, "<text x=\"%g\" y=\"24\" font-size=\"16\">%s</text>\n", left, html.EscapeString(title))
+	// the axes, the y axis in steps of 20% and the x axis in steps of 1, 5 or 10 years
+	fmt.Fprintf(&b, "<path d=\"M%g,%g V%g H%g\" fill=\"none\" stroke=\"black\"/>\n", left, top, height-bottom, width-right)
+	for s := 0; s <= 100; s += 20 {
+		fmt.Fprintf(&b, "<text x=\"%g\" y=\"%.1f\" text-anchor=\"end\">%d%%</text>\n", left-6, y(float64(s)/100)+4, s)
+	}
+	tick := 1.0
+	if maxTime > 20 {
+		tick = 10
+	} else if maxTime > 5 {
+		tick = 5
+	}
+	for t := 0.0; t <= maxTime; t += tick {
+		fmt.Fprintf(&b, "<text x=\"%.1f\" y=\"%g\" text-anchor=\"middle\">%g</text>\n", x(t), height-bottom+18, t)
+	}
+	fmt.Fprintf(&b, "<text x=\"%g\" y=\"%g\" text-anchor=\"middle\">years</text>\n", (left+width-right)/2, height-10)
+	// the confidence interval and the estimate
+	for _, v := range []func(KMPoint) float64{
+		func(p KMPoint) float64 { return p.Lower },
+		func(p KMPoint) float64 { return p.Upper }} {
+		fmt.Fprintf(&b, "<path d=\"%s\" fill=\"none\" stroke=\"steelblue\" stroke-dasharray=\"4 3\"/>\n", step(v))
+	}
+	fmt.Fprintf(&b, "<path d=\"%s\" fill=\"none\" stroke=\"steelblue\" stroke-width=\"2\"/>\n", step(func(p KMPoint) float64 { return p.Survival }))
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
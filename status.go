@@ -0,0 +1,100 @@
+package excel2json
+
+import (
+	"excel/helper"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// the status precedence when none is set, from the highest: dead and not followed over lost,
+// lost over other, and all of them over alive and refused
+var defaultPrecedence = []string{"D", "N", "L", "O", "A", "R"}
+
+// the status codes from the highest precedence, that disagreeing STATUS columns are reconciled with
+var precedence []string
+
+// loadPrecedence sets the status precedence from opts.Precedence, the status codes separated by
+// commas from the highest precedence, e.g. "D,N,L,O,A,R", or uses the default precedence.
+// The codes that are left out rank below the others.
+func loadPrecedence(e *log.Logger) {
+	precedence = defaultPrecedence
+	if opts.Precedence == "" {
+		return
+	}
+	precedence = nil
+	for _, c := range strings.Split(opts.Precedence, ",") {
+		c = strings.TrimSpace(c)
+		if c == "" || !helper.StringInSlice(0, c, codes) {
+			helper.CheckErr(e, fmt.Errorf("invalid status precedence: '%s', '%s' is not a status code", opts.Precedence, c))
+		} else if rank(c) < len(precedence) {
+			helper.CheckErr(e, fmt.Errorf("invalid status precedence: '%s', '%s' is there twice", opts.Precedence, c))
+		}
+		precedence = append(precedence, c)
+	}
+}
+
+// rank returns the place of a status code in the precedence, 0 is the highest,
+// or len(precedence) if it is not in the precedence.
+func rank(code string) int {
+	for i, c := range precedence {
+		if c == code {
+			return i
+		}
+	}
+	return len(precedence)
+}
+
+// the statuses of the STATUS columns of a row, reconciled to one status
+type statusCheck struct {
+	Status   string   // the reconciled status, empty if all the columns are empty
+	Values   []string // the non-empty values of the columns, in the order of the columns
+	Conflict bool     // the non-empty values are different
+	Note     string   // the audit note when the status of the first column with a value is overridden
+}
+
+// reconcileStatus reconciles the STATUS columns of the row m: empty columns take the status of
+// the others, and when they disagree the status with the highest precedence is kept, or the
+// first one if they have the same rank. A kept status that is not the first one gets an audit note.
+func reconcileStatus(m map[string]string, columns []string) statusCheck {
+	var c statusCheck
+	var first, kept string // the column of the first status and of the kept status
+	for _, col := range columns {
+		v := m[col]
+		if v == "" {
+			continue
+		}
+		c.Values = append(c.Values, v)
+		if c.Status == "" {
+			c.Status, first, kept = v, col, col
+		} else if v != c.Status {
+			c.Conflict = true
+			if rank(v) < rank(c.Status) {
+				c.Status, kept = v, col
+			}
+		}
+	}
+	if kept != first {
+		c.Note = fmt.Sprintf("status '%s' of %s overridden by '%s' of %s, by the status precedence %s",
+			m[first], first, c.Status, kept, strings.Join(precedence, ","))
+	}
+	return c
+}
+
+// conflictMsg returns the fix message of the different statuses of the columns.
+func (c statusCheck) conflictMsg() errMessage {
+	return errMessage{"status", "different Statuses: '" + strings.Join(c.Values, "', '") + "'"}
+}
+
+// invalidMsgs returns the fix messages of the statuses of the columns that are not status codes.
+func (c statusCheck) invalidMsgs() []errMessage {
+	msgs := []errMessage{}
+	seen := map[string]bool{}
+	for _, v := range c.Values {
+		if !seen[v] && !helper.StringInSlice(0, v, codes) {
+			msgs = append(msgs, errMessage{"code", "invalid value: '" + v + "'"})
+		}
+		seen[v] = true
+	}
+	return msgs
+}
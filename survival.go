@@ -0,0 +1,229 @@
+package excel2json
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// the outcomes of the survival dataset and the types of the events that are the outcome,
+// in the order of the columns
+var survivalOutcomes = [][2]string{
+	{"stroke", "stroke"},
+	{"tia", "tia"},
+	{"reoperation", "operation"},
+	{"sbe", "sbe"},
+	{"struct_valve_det", "struct_valve_det"},
+	{"arh", "arh"},
+	{"myocardial_infarction", "myocardial_infarction"},
+	{"perm_pacemaker", "perm_pacemaker"},
+	{"perivalvular_leak", "perivalvular_leak"},
+	{"deep_vein_thrombosis", "deep_vein_thrombosis"},
+	{"thromb_prost_valve", "thromb_prost_valve"},
+	{"hemolysis_dx", "hemolysis_dx"},
+}
+
+// the time to the first event of an outcome, or to the end of follow-up
+type outcomeTime struct {
+	Event int // 1 if the outcome happened, 0 if censored at the end of follow-up
+	Days  int // days from the index operation
+}
+
+// one patient of the survival dataset
+type survivalRow struct {
+	PTID         string
+	MRN          string
+	ResearchID   string
+	IndexDate    string // date of the first operation
+	EndDate      string // date of death or of the last contact
+	FollowupDays int
+	Death        int    // 1 if the patient died, 0 if censored
+	Cause        int    // the primary cause of death (PRM_DTH), 0 if the patient did not die
+	CensorReason string // lost_to_followup or alive, empty if the patient died
+	Outcomes     []outcomeTime
+}
+
+// daysBetween returns the days from date a to date b, both YYYY-MM-DD.
+func daysBetween(a string, b string) int {
+	t1, _ := time.Parse("2006-01-02", a)
+	t2, _ := time.Parse("2006-01-02", b)
+	return int(t2.Sub(t1).Hours() / 24)
+}
+
+// survivalRows returns one row per patient with an operation, time zero is the date of the
+// first operation. Follow-up ends at death or at the last date the patient was known to be alive.
+// Patients that died before their first operation are written to the errlog and left out.
+func survivalRows(e *log.Logger) []survivalRow {
+	// the operations are sorted by patient and date, the first one of a patient is the index operation
+	ptids := []string{}
+	index := map[string]*operation{}
+	for i := range allOperation {
+		o := &allOperation[i]
+		if o.PTID != "" && index[o.PTID] == nil {
+			index[o.PTID] = o
+			ptids = append(ptids, o.PTID)
+		}
+	}
+
+	deaths := map[string]*death{}
+	for i := range allDths {
+		d := &allDths[i]
+		if deaths[d.PTID] == nil || d.Date < deaths[d.PTID].Date {
+			deaths[d.PTID] = d
+		}
+	}
+	// the last date each patient was known to be alive, and whether the patient was lost then
+	lastContact, lost := map[string]string{}, map[string]bool{}
+	contact := func(id string, date string, isLost bool) {
+		if date > lastContact[id] {
+			lastContact[id] = date
+			lost[id] = isLost
+		} else if date == lastContact[id] && !isLost {
+			lost[id] = false
+		}
+	}
+	for _, l := range allLostFollowups {
+		date := l.Date
+		if l.LkaDate != nil && *l.LkaDate != "" {
+			date = *l.LkaDate
+		}
+		contact(l.PTID, date, true)
+	}
+	// the first date of each outcome on or after the index operation
+	first := map[string]map[string]string{}
+	for _, ev := range allEvents() {
+		id := *ev.PTID
+		if ev.Type == "death" || ev.Type == "lost_to_followup" || index[id] == nil {
+			continue
+		}
+		contact(id, *ev.Date, false)
+		start := index[id].Date
+		if *ev.Date < start || (ev.Type == "operation" && *ev.Date == start) {
+			continue
+		}
+		if first[id] == nil {
+			first[id] = map[string]string{}
+		}
+		if d, ok := first[id][ev.Type]; !ok || *ev.Date < d {
+			first[id][ev.Type] = *ev.Date
+		}
+	}
+
+	rows := []survivalRow{}
+	for _, id := range ptids {
+		op := index[id]
+		row := survivalRow{PTID: id, MRN: op.MRN, ResearchID: op.ResearchID, IndexDate: op.Date, EndDate: op.Date}
+		if d := deaths[id]; d != nil {
+			if d.Date < op.Date {
+				e.Println(strings.Join(d.Source.Path, ", "), "INFO: the patient died before the first operation, left out of the survival dataset:", id)
+				continue
+			}
+			row.EndDate, row.Death, row.Cause = d.Date, 1, d.PrmDth
+		} else {
+			row.CensorReason = "alive"
+			if lastContact[id] > row.EndDate {
+				row.EndDate = lastContact[id]
+				if lost[id] {
+					row.CensorReason = "lost_to_followup"
+				}
+			}
+		}
+		row.FollowupDays = daysBetween(row.IndexDate, row.EndDate)
+		for _, o := range survivalOutcomes {
+			t := outcomeTime{0, row.FollowupDays}
+			// an outcome after the end of follow-up is censored at the end
+			if d, ok := first[id][o[1]]; ok && d <= row.EndDate {
+				t = outcomeTime{1, daysBetween(row.IndexDate, d)}
+			}
+			row.Outcomes = append(row.Outcomes, t)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// survivalColumns returns the column names of the survival dataset.
+func survivalColumns() []string {
+	columns := []string{"patient_id", "mrn", "research_id", "index_date", "end_date", "followup_days", "death", "censor_reason"}
+	for _, o := range survivalOutcomes {
+		columns = append(columns, o[0], o[0]+"_days")
+	}
+	return columns
+}
+
+// writeSurvival writes the survival dataset to path, a Parquet file if the path ends with
+// .parquet, or else a CSV file.
+func writeSurvival(e *log.Logger, path string) error {
+	rows := survivalRows(e)
+	var err error
+	if strings.HasSuffix(strings.ToLower(path), ".parquet") {
+		err = writeSurvivalParquet(path, rows)
+	} else {
+		err = writeSurvivalCSV(path, rows)
+	}
+	if err == nil {
+		fmt.Println(len(rows), "patients in the survival dataset, see", path)
+	}
+	return err
+}
+
+// writeSurvivalCSV writes the survival dataset to a CSV file with a header row.
+func writeSurvivalCSV(path string, rows []survivalRow) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := csv.NewWriter(file)
+	w.Write(survivalColumns())
+	for _, r := range rows {
+		record := []string{r.PTID, r.MRN, r.ResearchID, r.IndexDate, r.EndDate,
+			strconv.Itoa(r.FollowupDays), strconv.Itoa(r.Death), r.CensorReason}
+		for _, t := range r.Outcomes {
+			record = append(record, strconv.Itoa(t.Event), strconv.Itoa(t.Days))
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// writeSurvivalParquet writes the survival dataset to a Parquet file,
+// the text columns are UTF8 strings and the others 32-bit integers.
+func writeSurvivalParquet(path string, rows []survivalRow) error {
+	schema := []string{}
+	for i, c := range survivalColumns() {
+		if i < 5 || c == "censor_reason" {
+			schema = append(schema, "name="+c+", type=BYTE_ARRAY, convertedtype=UTF8")
+		} else {
+			schema = append(schema, "name="+c+", type=INT32")
+		}
+	}
+	file, err := local.NewLocalFileWriter(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w, err := writer.NewCSVWriter(schema, file, 1)
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		record := []interface{}{r.PTID, r.MRN, r.ResearchID, r.IndexDate, r.EndDate,
+			int32(r.FollowupDays), int32(r.Death), r.CensorReason}
+		for _, t := range r.Outcomes {
+			record = append(record, int32(t.Event), int32(t.Days))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	return w.WriteStop()
+}
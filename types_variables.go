@@ -18,16 +18,42 @@ var (
 	allTHRM          []general      // store thromb_prost_valve events
 	alllHEML         []general      // store hemolysis_dx events
 	allLKA           []followups    // store last_known_alive events
-	allFix           []general      // store fix events
+	allFix           []fix          // store fix events
+	allIndexOps      []operation    // store the index operations of follow_up rows that only time events
+	allWorkbooks     []workbook     // store the workbooks that have been read
 	codes            []string       // status codes
 	nums             []int          // int values for various codes
 	floats           []float64      // float points values for various codes
+	opts             Options        // options of the current run
 )
 
+// Options holds the command-line options that change how events are created and written.
+type Options struct {
+	LegacyFix    bool   // write fix events in the old "general" format with placeholder dates
+	Root         string // paths in the source of events are relative to this folder
+	ValidateOnly bool   // only check the data and report its quality, without writing events
+	HTMLPath     string // path to the HTML data-quality report, empty for no report
+	StatePath    string // path to the state file, empty to read all workbooks
+	Columns      string // path to the columns file, empty to ask for it
+	Sheets       string // path to the sheet classes file, empty for the follow_up rules
+	Crosswalk    string // path to the crosswalk of PTID, MRN and research id, empty to leave them empty
+	CenturyPivot int    // two-digit birth years up to this one are in the 2000s, the others in the 1900s
+	Aliases      string // path to the file of confirmed PTID aliases, empty for no aliases
+	Duplicates   string // path to the report of likely duplicate PTIDs, empty for no report
+	Survival     string // path to the survival dataset, CSV or Parquet (*.parquet), empty for no dataset
+	EarlyWindow  string // days after an operation that events are early, or "hospital" for until discharge
+	Procedures   string // path to the procedure vocabulary file, empty to split REOPSURG on ";"
+	Organisms    string // path to the organism table file, empty for the default table
+	Rules        string // path to the rules file that the rows are checked with
+	Precedence   string // the status codes from the highest precedence, separated by commas, empty for D,N,L,O,A,R
+	IndexOps     bool   // the DATEOR of each follow_up row is an operation event as well
+}
+
 // type source
 type source struct {
 	Type string   `json:"type"`
 	Path []string `json:"path"`
+	Rows []rowRef `json:"rows"` // one row for each path
 }
 
 // error message
@@ -38,6 +64,7 @@ type errMessage struct {
 
 // operation
 type operation struct {
+	ID         string       `json:"id"` // set by linkOperations
 	Type       string       `json:"type"`
 	MRN        string       `json:"mrn"`
 	ResearchID string       `json:"research_id"`
@@ -45,10 +72,15 @@ type operation struct {
 	PTID       string       `json:"patient_id"`
 	Date       string       `json:"date"`
 	DateEst    int          `json:"date_est"`
+	AgeAtEvent *int         `json:"age_at_event,omitempty"`
 	Surgeon    string       `json:"surgeon"`
 	Surgeries  []string     `json:"surgeries"`
-	Children   []string     `json:"children"`
-	Parent     *int         `json:"parent"`
+	Surgery    *string      `json:"surgery_text"` // the free text of the surgeries (REOPSURG)
+	Reason     *string      `json:"reason"`
+	Survival   *int         `json:"survival"` // survived the re-operation (REOPSURVIVAL)
+	Discharge  *string      `json:"discharge_date,omitempty"`
+	Children   []string     `json:"children"` // ids of the operations that followed this one
+	Parent     *string      `json:"parent"`   // id of the operation this one followed
 	Notes      *string      `json:"notes"`
 	Source     source       `json:"source"`
 	Fix        []errMessage `json:"fix"`
@@ -62,7 +94,9 @@ type followups struct {
 	PTID       string       `json:"patient_id"`
 	Date       string       `json:"date"`
 	DateEst    int          `json:"date_est"`
+	AgeAtEvent *int         `json:"age_at_event,omitempty"`
 	Status     *string      `json:"status,omitempty"` // last_known_alive events don't have status field
+	Audit      *string      `json:"status_audit,omitempty"`
 	Notes      *string      `json:"notes"`
 	Unusual    *string      `json:"unusual"`
 	Plat       int          `json:"anti_platelet"`
@@ -80,13 +114,26 @@ type death struct {
 	PTID       string       `json:"patient_id"`
 	Date       string       `json:"date"`
 	DateEst    int          `json:"date_est"`
+	AgeAtEvent *int         `json:"age_at_event,omitempty"`
 	Reason     string       `json:"reason"`
 	PrmDth     int          `json:"primary_cause"`
-	Operative  int          `json:"operative"`
+	Operative  int          `json:"operative"`       // set by setOperative from the dates of the operations
+	CodedOp    *int         `json:"coded_operative"` // 1 if SURVIVAL is 0, 0 if SURVIVAL is 1
+	Evidence   *opEvidence  `json:"operative_evidence"`
 	Source     source       `json:"source"`
 	Fix        []errMessage `json:"fix"`
 }
 
+// the operation that decided if a death was operative
+type opEvidence struct {
+	OperationID     string  `json:"operation_id"` // empty for the DATEOR of a follow_up row without -index-operations
+	OperationDate   string  `json:"operation_date"`
+	DaysAfter       int     `json:"days_after"` // days from the operation to the death
+	Discharge       *string `json:"discharge_date"`
+	Within30Days    bool    `json:"within_30_days"`
+	BeforeDischarge bool    `json:"before_discharge"`
+}
+
 // including stroke and tia
 type te struct {
 	Type       string       `json:"type"`
@@ -95,9 +142,10 @@ type te struct {
 	PTID       string       `json:"patient_id"`
 	Date       string       `json:"date"`
 	DateEst    int          `json:"date_est"`
+	AgeAtEvent *int         `json:"age_at_event,omitempty"`
 	Outcome    int          `json:"outcome"`
 	Agents     int          `json:"anti_agents"`
-	When       int          `json:"when,omitempty"` // only stroke events
+	When       int          `json:"when,omitempty"` // 1 early, 2 late after the nearest preceding operation
 	Source     source       `json:"source"`
 	Fix        []errMessage `json:"fix"`
 }
@@ -110,12 +158,37 @@ type lostFollowup struct {
 	PTID       string       `json:"patient_id"`
 	Date       string       `json:"date"`
 	DateEst    int          `json:"date_est"`
+	AgeAtEvent *int         `json:"age_at_event,omitempty"`
 	LkaDate    *string      `json:"lka_date"`
 	Notes      *string      `json:"notes"`
 	Source     source       `json:"source"`
 	Fix        []errMessage `json:"fix"`
 }
 
+// fix events, created when a row cannot become an event of its intended type
+type fix struct {
+	Type       string  `json:"type"`
+	MRN        string  `json:"mrn"`
+	ResearchID string  `json:"research_id"`
+	PTID       string  `json:"patient_id"`
+	Date       *string `json:"date"`       // always null, the date is what needs fixing
+	EventType  string  `json:"event_type"` // the type of event the row was meant to create
+	Reason     string  `json:"reason"`     // missing_date, invalid_date, invalid_code, conflicting_sources or rule
+	Column     string  `json:"column"`     // the column that needs fixing
+	Row        rowRef  `json:"row"`
+	Msg        string  `json:"msg"`
+	Source     source  `json:"source"`
+}
+
+// the row of an excel sheet that an event comes from
+type rowRef struct {
+	Path      string            `json:"path"`
+	SheetName string            `json:"sheet_name"`
+	Sheet     int               `json:"sheet"`   // sheet number, starting from 1
+	Row       int               `json:"row"`     // row number as shown in excel, the header row is 1
+	Columns   map[string]string `json:"columns"` // column names and the values of the cells used
+}
+
 // type of events that share the same variables,
 // including arh, myocardial_infarction, perm_pacemaker, struct_valve_det,
 // perivalvular_leak, deep_vein_thrombosis, thromb_prost_valve, hemolysis_dx events
@@ -126,9 +199,13 @@ type general struct {
 	PTID       string       `json:"patient_id"`
 	Date       string       `json:"date"`
 	DateEst    int          `json:"date_est"`
+	AgeAtEvent *int         `json:"age_at_event,omitempty"`
 	Organism   *string      `json:"organism,omitempty"` // only sbe events have
 	Code       int          `json:"code,omitempty"`     // only arh events have
 	Msg        string       `json:"msg,omitempty"`      // some events don't have msg field
+	When       int          `json:"when,omitempty"`     // 1 early, 2 late after the nearest preceding operation
+	Species    *string      `json:"organism_name,omitempty"`
+	Group      *string      `json:"organism_group,omitempty"`
 	Source     source       `json:"source"`
 	Fix        []errMessage `json:"fix,omitempty"` // fix events don't need fix field
 }
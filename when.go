@@ -0,0 +1,86 @@
+package excel2json
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// the early window when none is set, in days after the operation
+const defaultEarlyDays = 30
+
+// nearestOperation returns the latest operation of the patient on or before the date, or nil.
+// The operations must be sorted by patient and date, as linkOperations leaves them. The index
+// operations of the follow_up rows that are not operation events are used as well, so that
+// events are timed from DATEOR without -index-operations.
+func nearestOperation(ptid string, date string) *operation {
+	var op *operation
+	for i := range allOperation {
+		o := &allOperation[i]
+		if o.PTID == ptid && o.Date <= date {
+			op = o
+		} else if o.PTID > ptid {
+			break
+		}
+	}
+	for i := range allIndexOps {
+		o := &allIndexOps[i]
+		if o.PTID == ptid && o.Date <= date && (op == nil || o.Date > op.Date) {
+			op = o
+		}
+	}
+	return op
+}
+
+// setWhen sets the when field of the stroke, tia and other morbidity events: 1 (early) if the
+// event is in the early window of the nearest preceding operation of the patient, else 2 (late).
+// The window of opts.EarlyWindow is a number of days after the operation, or "hospital" for
+// the days until the discharge date of the operation. An event that cannot be classified,
+// without an earlier operation or its discharge date, gets a fix message.
+func setWhen() error {
+	days, hospital := defaultEarlyDays, opts.EarlyWindow == "hospital"
+	if opts.EarlyWindow != "" && !hospital {
+		var err error
+		if days, err = strconv.Atoi(opts.EarlyWindow); err != nil || days < 0 {
+			return fmt.Errorf("invalid early window: '%s', it is a number of days or hospital", opts.EarlyWindow)
+		}
+	}
+	// when returns the when of an event and a fix message if it cannot be classified
+	when := func(ptid string, date string) (int, *errMessage) {
+		op := nearestOperation(ptid, date)
+		if op == nil {
+			return 0, &errMessage{"when", "cannot classify as early or late, there is no operation on or before the event"}
+		}
+		if hospital {
+			if op.Discharge == nil {
+				return 0, &errMessage{"when", "cannot classify as early or late, the discharge date of the operation of '" + op.Date + "' is unknown"}
+			} else if date <= *op.Discharge {
+				return 1, nil
+			}
+			return 2, nil
+		}
+		if daysBetween(op.Date, date) <= days {
+			return 1, nil
+		}
+		return 2, nil
+	}
+
+	for _, s := range [][]te{allStroke, allTIA} {
+		for i := range s {
+			o := &s[i]
+			var msg *errMessage
+			if o.When, msg = when(o.PTID, o.Date); msg != nil {
+				o.Fix = append(o.Fix, *msg)
+			}
+		}
+	}
+	for _, s := range [][]general{allSBE, allARH, allFUMI, allFUPACE, allSVD, allPVL, allDVT, allTHRM, alllHEML} {
+		for i := range s {
+			o := &s[i]
+			var msg *errMessage
+			if o.When, msg = when(o.PTID, o.Date); msg != nil {
+				o.Fix = append(o.Fix, *msg)
+			}
+		}
+	}
+	return nil
+}
@@ -7,7 +7,7 @@ import (
 
 // WriteToJSON writes from different types of slice to JSON objects
 func WriteToJSON(jsonFile *os.File, allARH []general, allDVT []general, allDths []death, allFUMI []general,
-	allFUPACE []general, allFix []general, allFollowUps []followups, allLKA []followups,
+	allFUPACE []general, allFix []fix, allFollowUps []followups, allLKA []followups,
 	allOperation []operation, allPVL []general, allSBE []general, allSVD []general,
 	allStroke []te, allTHRM []general, allTIA []te, alllHEML []general, allLostFollowups []lostFollowup) {
 
@@ -45,7 +45,12 @@ func WriteToJSON(jsonFile *os.File, allARH []general, allDVT []general, allDths
 	}
 	// fix events
 	for _, o := range allFix {
-		helper.WriteTOFile(jsonFile, o)
+		// write the old format with placeholder dates if asked
+		if opts.LegacyFix {
+			helper.WriteTOFile(jsonFile, o.legacy())
+		} else {
+			helper.WriteTOFile(jsonFile, o)
+		}
 	}
 	// operation events
 	for _, o := range allOperation {